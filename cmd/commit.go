@@ -3,14 +3,20 @@ package cmd
 import (
 	"context"
 	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
 	"strings"
 
 	"github.com/EkeMinusYou/gelf/internal/ai"
 	"github.com/EkeMinusYou/gelf/internal/config"
 	"github.com/EkeMinusYou/gelf/internal/git"
 	"github.com/EkeMinusYou/gelf/internal/ui"
-	"github.com/charmbracelet/lipgloss"
+	"github.com/EkeMinusYou/gelf/internal/webhook"
 	"github.com/spf13/cobra"
+	"golang.org/x/term"
 )
 
 var commitCmd = &cobra.Command{
@@ -26,32 +32,69 @@ var (
 	model          string
 	commitLanguage string
 	yesFlag        bool
+	noPrelude      bool
+	signoff        bool
+	candidates     int
+	concise        bool
+	gitmoji        bool
+	amend          bool
+	migrationNotes bool
+	estimate       bool
+	scope          string
+	allowFallback  bool
+	showFinal      bool
+	fromStdin      bool
+	outputPath     string
+	contextLines   int
+	excludes       []string
+	maxSubjectLen  int
+	noVerify       bool
+	stageAll       bool
 )
 
-var warningStyle = lipgloss.NewStyle().
-	Foreground(lipgloss.Color("3")). // イエロー
-	Bold(true)
-
 func init() {
 	commitCmd.Flags().BoolVar(&dryRun, "dry-run", false, "Generate message only without committing")
 	commitCmd.Flags().BoolVar(&quiet, "quiet", false, "Don't show diff output (only with --dry-run)")
 	commitCmd.Flags().StringVar(&model, "model", "", "Override default model for this generation")
 	commitCmd.Flags().StringVar(&commitLanguage, "language", "", "Language for commit message generation (e.g., english, japanese)")
 	commitCmd.Flags().BoolVar(&yesFlag, "yes", false, "Automatically approve commit message without interactive confirmation")
+	commitCmd.Flags().IntVar(&candidates, "candidates", 1, "Number of alternative commit messages to generate and choose from (1-8)")
+	commitCmd.Flags().BoolVar(&concise, "concise", false, "Generate the shortest valid Conventional Commits message (e.g. \"fix: typo\")")
+	commitCmd.Flags().BoolVar(&gitmoji, "gitmoji", false, "Prefix the subject with the gitmoji matching its type (e.g. ✨ feat, 🐛 fix)")
+	commitCmd.Flags().BoolVar(&amend, "amend", false, "Regenerate the message for the last commit and amend it with the staged changes")
+	commitCmd.Flags().BoolVar(&migrationNotes, "migration-notes", false, "Add a BREAKING CHANGE footer with migration notes when the change is breaking")
+	commitCmd.Flags().IntVar(&maxSubjectLen, "max-subject-length", 0, "Longest subject line allowed; longer subjects are shortened (default: commit.max_subject_length or 72)")
+	commitCmd.Flags().StringVar(&scope, "scope", "", "Use this Conventional Commits scope (must be listed in commit.scopes when configured)")
+	commitCmd.Flags().BoolVarP(&stageAll, "stage-all", "a", false, "Stage all changes, including untracked files, before generating (git add -A)")
+	commitCmd.Flags().BoolVar(&noVerify, "no-verify", false, "Skip the pre-commit and commit-msg hooks (passed to git commit)")
+	commitCmd.Flags().BoolVar(&signoff, "signoff", false, "Add a Signed-off-by trailer using git user.name and user.email")
+	commitCmd.Flags().BoolVar(&showFinal, "show-final", false, "With --dry-run, print the message exactly as it would be committed, including ticket and trailers")
+	commitCmd.Flags().StringVar(&outputPath, "output", "", "With --dry-run, write the message to this file instead of stdout (e.g. .git/COMMIT_EDITMSG)")
+	commitCmd.Flags().BoolVar(&allowFallback, "allow-fallback", false, "Use a message built from the diff summary if AI generation fails (with --yes or --dry-run)")
+	commitCmd.Flags().IntVarP(&contextLines, "context", "U", 0, "Number of unchanged lines shown around each change in the diff (default: diff.context_lines or 5)")
+	commitCmd.Flags().StringArrayVar(&excludes, "exclude", nil, "Leave files matching this glob or git pathspec out of the diff (repeatable, added to diff.exclude)")
+	commitCmd.Flags().BoolVar(&fromStdin, "stdin", false, "Read the diff from standard input instead of the staged changes (with --dry-run, --yes or --estimate)")
+	commitCmd.Flags().BoolVar(&estimate, "estimate", false, "Show the prompt's input token count and estimated cost without generating a message")
+	commitCmd.Flags().BoolVar(&noPrelude, "no-prelude", false, "Don't include the repository prompt prelude (.gelf/prompt.md)")
 }
 
 func runCommit(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+	// Cancel in-flight AI requests on Ctrl+C outside the TUI
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	// A diff on stdin can be previewed anywhere; committing needs a repository
+	if !fromStdin || yesFlag {
+		if err := git.EnsureRepo(); err != nil {
+			return err
+		}
+	}
 
 	cfg, err := config.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
-	if !cfg.UseColor() {
-		warningStyle = lipgloss.NewStyle() // No color
-	}
-
 	if model != "" {
 		cfg.FlashModel = model
 	}
@@ -61,13 +104,96 @@ func runCommit(cmd *cobra.Command, args []string) error {
 		cfg.CommitLanguage = commitLanguage
 	}
 
-	diff, err := git.GetStagedDiff()
-	if err != nil {
-		return fmt.Errorf("failed to get staged changes: %w", err)
+	if cmd.Flags().Changed("max-subject-length") {
+		if maxSubjectLen <= 0 {
+			return fmt.Errorf("--max-subject-length must be positive")
+		}
+		cfg.CommitMaxSubjectLength = maxSubjectLen
+	}
+
+	if cmd.Flags().Changed("context") {
+		if contextLines < 0 {
+			return fmt.Errorf("--context must not be negative")
+		}
+		cfg.DiffContextLines = contextLines
+	}
+	diffOptions := git.DiffOptions{
+		ContextLines: cfg.DiffContextLines,
+		Exclude:      append(slices.Clone(cfg.DiffExclude), excludes...),
+	}
+
+	if candidates < 1 || candidates > 8 {
+		return fmt.Errorf("--candidates must be between 1 and 8")
+	}
+
+	if showFinal && !dryRun {
+		return fmt.Errorf("--show-final can only be used with --dry-run")
+	}
+
+	if outputPath != "" && !dryRun {
+		return fmt.Errorf("--output can only be used with --dry-run")
+	}
+
+	if scope != "" && len(cfg.CommitScopes) > 0 && !slices.Contains(cfg.CommitScopes, scope) {
+		return fmt.Errorf("--scope %q is not in commit.scopes (%s)", scope, strings.Join(cfg.CommitScopes, ", "))
+	}
+
+	if cfg.CommitAutoScopes && len(cfg.CommitScopes) == 0 {
+		detected, err := git.DetectScopes()
+		if err != nil {
+			return fmt.Errorf("failed to detect scopes: %w", err)
+		}
+		cfg.CommitScopes = detected
+	}
+
+	if fromStdin && amend {
+		return fmt.Errorf("--stdin cannot be used with --amend")
+	}
+	if fromStdin && stageAll {
+		return fmt.Errorf("--stdin cannot be used with --stage-all")
+	}
+	if fromStdin && !dryRun && !yesFlag && !estimate {
+		// The TUI needs the terminal on stdin
+		return fmt.Errorf("--stdin requires --dry-run, --yes or --estimate")
+	}
+
+	if stageAll {
+		if err := git.StageAll(); err != nil {
+			return err
+		}
+	}
+
+	var diff string
+	var stdinExcluded []string
+	if fromStdin {
+		diff, err = readStdinDiff()
+		if err != nil {
+			return err
+		}
+		// git did not produce this diff, so apply the exclusions here
+		diff, stdinExcluded = git.ExcludeFromDiff(diff, diffOptions.Exclude)
+	} else if amend {
+		// Amending works from HEAD's tree even when nothing new is staged
+		diff, err = git.GetAmendDiff(diffOptions)
+		if err != nil {
+			return fmt.Errorf("failed to get changes to amend: %w", err)
+		}
+	} else {
+		diff, err = git.GetStagedDiff(diffOptions)
+		if err != nil {
+			return fmt.Errorf("failed to get staged changes: %w", err)
+		}
+	}
+
+	if diff == "" && !fromStdin && !amend && !dryRun && !yesFlag && !estimate && term.IsTerminal(int(os.Stdin.Fd())) {
+		diff, err = pickAndStageFiles(diffOptions)
+		if err != nil {
+			return err
+		}
 	}
 
 	if diff == "" {
-		message := warningStyle.Render("⚠ No staged changes found. Please stage some changes first with 'git add'.")
+		message := ui.RenderWarning("⚠ No staged changes found. Please stage some changes first with 'git add'.")
 		if dryRun {
 			fmt.Fprintf(cmd.ErrOrStderr(), "%s\n", message)
 			return fmt.Errorf("no staged changes")
@@ -82,6 +208,63 @@ func runCommit(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to create AI client: %w", err)
 	}
 
+	if !noPrelude {
+		if err := aiClient.UseRepoPromptPrelude(); err != nil {
+			return err
+		}
+	}
+
+	branch, err := git.GetCurrentBranch()
+	if err != nil || branch == "HEAD" {
+		// Detached HEAD or an unborn branch has no meaningful branch name
+		branch = ""
+	}
+
+	finalizer, err := git.NewFinalizer(branch, cfg.CommitTicketPattern, cfg.CommitTicketPlacement, signoff || cfg.CommitSignoff)
+	if err != nil {
+		return err
+	}
+
+	input := ai.CommitMessageInput{
+		Diff:             diff,
+		Language:         cfg.CommitLanguage,
+		Branch:           branch,
+		Concise:          concise,
+		Gitmoji:          gitmoji || cfg.CommitGitmoji,
+		MigrationNotes:   migrationNotes,
+		Scope:            scope,
+		Scopes:           cfg.CommitScopes,
+		MaxSubjectLength: cfg.CommitMaxSubjectLength,
+		Types:            cfg.CommitTypes,
+	}
+
+	commitOptions := ui.CommitOptions{
+		Finalizer:        finalizer,
+		Candidates:       candidates,
+		Amend:            amend,
+		MaxSubjectLength: cfg.CommitMaxSubjectLength,
+		// Custom prompts may not ask for Conventional Commits
+		Lint:     cfg.CommitPromptTemplate == "",
+		Types:    cfg.CommitTypes,
+		NoVerify: noVerify,
+	}
+	if amend {
+		previous, err := git.HeadMessage()
+		if err != nil {
+			return fmt.Errorf("failed to read the last commit message: %w", err)
+		}
+		commitOptions.PreviousMessage = previous
+	}
+
+	if estimate {
+		tokens, err := aiClient.CountCommitTokens(ctx, input)
+		if err != nil {
+			return fmt.Errorf("failed to estimate token usage: %w", err)
+		}
+		printTokenEstimate(cmd, cfg, cfg.FlashModel, tokens)
+		return nil
+	}
+
 	if dryRun {
 		if !quiet {
 			diffSummary := git.ParseDiffSummary(diff)
@@ -89,6 +272,9 @@ func runCommit(cmd *cobra.Command, args []string) error {
 				fmt.Fprintf(cmd.ErrOrStderr(), "=== Changed Files ===\n")
 				for _, file := range diffSummary.Files {
 					var changes []string
+					if file.Binary {
+						changes = append(changes, "binary")
+					}
 					if file.AddedLines > 0 {
 						changes = append(changes, fmt.Sprintf("+%d", file.AddedLines))
 					}
@@ -97,9 +283,9 @@ func runCommit(cmd *cobra.Command, args []string) error {
 					}
 
 					if len(changes) > 0 {
-						fmt.Fprintf(cmd.ErrOrStderr(), "%s (%s)\n", file.Name, strings.Join(changes, ", "))
+						fmt.Fprintf(cmd.ErrOrStderr(), "%s (%s)\n", file.DisplayName(), strings.Join(changes, ", "))
 					} else {
-						fmt.Fprintf(cmd.ErrOrStderr(), "%s\n", file.Name)
+						fmt.Fprintf(cmd.ErrOrStderr(), "%s\n", file.DisplayName())
 					}
 				}
 				fmt.Fprintf(cmd.ErrOrStderr(), "\n=== Full Diff ===\n%s\n\n", diff)
@@ -108,41 +294,208 @@ func runCommit(cmd *cobra.Command, args []string) error {
 			}
 		}
 
-		message, err := aiClient.GenerateCommitMessage(ctx, diff, cfg.CommitLanguage)
+		excluded := stdinExcluded
+		if !fromStdin {
+			excluded, err = git.GetExcludedFiles(diffOptions, amend)
+			if err != nil {
+				return fmt.Errorf("failed to list excluded files: %w", err)
+			}
+		}
+		if len(excluded) > 0 {
+			fmt.Fprintf(cmd.ErrOrStderr(), "=== Excluded Files ===\n%s\n\n", strings.Join(excluded, "\n"))
+		}
+
+		messages, err := generateCandidates(ctx, cmd, cfg, aiClient, input)
 		if err != nil {
-			return fmt.Errorf("failed to generate commit message: %w", err)
+			return err
+		}
+
+		if showFinal {
+			for i, message := range messages {
+				messages[i] = commitOptions.Finalize(message)
+			}
+		}
+
+		if outputPath != "" {
+			return writeFileWithParents(outputPath, strings.Join(messages, "\n\n")+"\n")
 		}
 
-		fmt.Print(message)
+		fmt.Print(strings.Join(messages, "\n\n"))
 		return nil
 	}
 
 	// Handle --yes flag: automatically approve and commit
 	if yesFlag {
-		message, err := aiClient.GenerateCommitMessage(ctx, diff, cfg.CommitLanguage)
+		messages, err := generateCandidates(ctx, cmd, cfg, aiClient, input)
 		if err != nil {
-			return fmt.Errorf("failed to generate commit message: %w", err)
+			return err
 		}
 
+		// With multiple candidates, --yes picks the first one
+		message := commitOptions.Finalize(messages[0])
+
 		// Display the generated commit message
 		fmt.Printf("Generated commit message:\n%s\n\n", message)
+		if commitOptions.Lint {
+			if err := git.ValidateConventionalCommit(message, commitOptions.Types); err != nil {
+				printLintProblems(cmd.ErrOrStderr(), err)
+			}
+		}
+
+		if amend {
+			previousSubject, _, _ := strings.Cut(commitOptions.PreviousMessage, "\n")
+			fmt.Printf("Previous subject:\n%s\n\n", previousSubject)
+
+			if err := git.AmendCommit(ctx, message, noVerify); err != nil {
+				return fmt.Errorf("failed to amend commit: %w", err)
+			}
+
+			fmt.Println("✅ Successfully amended the last commit!")
+			notifyCommit(ctx, cmd, cfg, branch, message)
+			return nil
+		}
 
 		// Commit the changes
-		if err := git.CommitChanges(message); err != nil {
+		if err := git.CommitChanges(ctx, message, noVerify); err != nil {
 			return fmt.Errorf("failed to commit changes: %w", err)
 		}
 
 		fmt.Println("✅ Successfully committed changes!")
+		notifyCommit(ctx, cmd, cfg, branch, message)
 		return nil
 	}
 
-	if !cfg.UseColor() {
-		ui.DisableColor()
-	}
-	tui := ui.NewTUI(aiClient, diff, cfg.CommitLanguage)
+	ui.SetMaxSummaryFiles(cfg.UIMaxSummaryFiles)
+	tui := ui.NewTUI(ctx, aiClient, input, commitOptions)
 	if err := tui.Run(); err != nil {
 		return fmt.Errorf("TUI error: %w", err)
 	}
+	if message, ok := tui.CommittedMessage(); ok {
+		notifyCommit(ctx, cmd, cfg, branch, message)
+	}
 
 	return nil
 }
+
+// pickAndStageFiles lets the user choose unstaged files to stage when
+// nothing is staged yet, returning the resulting staged diff.
+func pickAndStageFiles(opts git.DiffOptions) (string, error) {
+	files, err := git.UnstagedFiles()
+	if err != nil || len(files) == 0 {
+		return "", err
+	}
+
+	selected, ok, err := ui.PickFiles("Nothing is staged. Select files to stage:", files)
+	if err != nil {
+		return "", err
+	}
+	if !ok || len(selected) == 0 {
+		return "", nil
+	}
+
+	if err := git.StageFiles(selected); err != nil {
+		return "", err
+	}
+	diff, err := git.GetStagedDiff(opts)
+	if err != nil {
+		return "", fmt.Errorf("failed to get staged changes: %w", err)
+	}
+	return diff, nil
+}
+
+// readStdinDiff reads a diff piped on standard input. It refuses to wait on
+// an interactive terminal, where no diff is coming.
+func readStdinDiff() (string, error) {
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		return "", fmt.Errorf("--stdin requires a diff piped on standard input (e.g. git diff --staged | gelf commit --stdin --dry-run)")
+	}
+
+	data, err := io.ReadAll(os.Stdin)
+	if err != nil {
+		return "", fmt.Errorf("failed to read diff from standard input: %w", err)
+	}
+	if strings.TrimSpace(string(data)) == "" {
+		return "", fmt.Errorf("no diff on standard input")
+	}
+	return string(data), nil
+}
+
+// generateCandidates generates commit message candidates, falling back to a
+// message built from the diff summary when generation fails and
+// --allow-fallback is set.
+func generateCandidates(ctx context.Context, cmd *cobra.Command, cfg *config.Config, aiClient *ai.VertexAIClient, input ai.CommitMessageInput) ([]string, error) {
+	messages, err := aiClient.GenerateCommitMessageCandidates(ctx, input, candidates)
+	if err == nil {
+		return messages, nil
+	}
+	if !allowFallback || ctx.Err() != nil {
+		return nil, fmt.Errorf("failed to generate commit message: %w", err)
+	}
+
+	message, fallbackErr := fallbackCommitMessage(cfg, input.Diff)
+	if fallbackErr != nil {
+		return nil, fmt.Errorf("failed to generate commit message: %w (fallback also failed: %v)", err, fallbackErr)
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "%s\n", ui.RenderWarning(fmt.Sprintf("⚠ AI generation failed, using fallback message: %v", err)))
+	return []string{message}, nil
+}
+
+// fallbackCommitMessage renders commit.fallback_format from the diff summary.
+func fallbackCommitMessage(cfg *config.Config, diff string) (string, error) {
+	tmpl, err := config.ParseCommitFallbackTemplate(cfg.CommitFallbackFormat)
+	if err != nil {
+		return "", err
+	}
+
+	summary := git.ParseDiffSummary(diff)
+	names := make([]string, len(summary.Files))
+	for i, file := range summary.Files {
+		names[i] = file.Name
+	}
+
+	data := config.CommitFallbackData{
+		Count:   len(names),
+		Files:   strings.Join(names, ", "),
+		Summary: fmt.Sprintf("%d files", len(names)),
+	}
+	if len(names) == 1 {
+		data.Summary = filepath.Base(names[0])
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// printTokenEstimate prints the input token count for model and, when a
+// price is configured under pricing, the estimated input cost.
+func printTokenEstimate(cmd *cobra.Command, cfg *config.Config, model string, tokens int) {
+	out := cmd.OutOrStdout()
+	fmt.Fprintf(out, "Model:          %s\n", model)
+	fmt.Fprintf(out, "Input tokens:   %d\n", tokens)
+	if cost, ok := cfg.EstimateInputCost(model, tokens); ok {
+		fmt.Fprintf(out, "Estimated cost: $%.4f (input only)\n", cost)
+	} else {
+		fmt.Fprintf(out, "Estimated cost: unknown (set pricing.%s.input_per_million in gelf.yml)\n", model)
+	}
+}
+
+// notifyCommit posts the commit to the hooks.on_commit webhook, if
+// configured. Failures are reported as warnings and never fail the command.
+func notifyCommit(ctx context.Context, cmd *cobra.Command, cfg *config.Config, branch, message string) {
+	if cfg.HooksOnCommit == "" {
+		return
+	}
+
+	repo := ""
+	if repoRoot, err := git.GetRepoRoot(); err == nil {
+		repo = filepath.Base(repoRoot)
+	}
+
+	payload := webhook.NewCommitPayload(repo, branch, message, amend)
+	if err := webhook.Post(ctx, cfg.HooksOnCommit, payload, webhook.DefaultTimeout); err != nil {
+		fmt.Fprintf(cmd.ErrOrStderr(), "%s\n", ui.RenderWarning(fmt.Sprintf("⚠ Commit webhook failed: %v", err)))
+	}
+}
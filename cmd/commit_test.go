@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/EkeMinusYou/gelf/internal/config"
+)
+
+func fileDiff(name string) string {
+	return strings.Join([]string{
+		"diff --git a/" + name + " b/" + name,
+		"--- a/" + name,
+		"+++ b/" + name,
+		"@@ -1 +1 @@",
+		"-old",
+		"+new",
+	}, "\n")
+}
+
+func TestFallbackCommitMessage(t *testing.T) {
+	twoFiles := fileDiff("internal/git/diff.go") + "\n" + fileDiff("README.md")
+
+	tests := []struct {
+		name   string
+		format string
+		diff   string
+		want   string
+	}{
+		{
+			name:   "default format with a single file",
+			format: config.DefaultCommitFallbackFormat,
+			diff:   fileDiff("internal/git/diff.go"),
+			want:   "chore: update diff.go",
+		},
+		{
+			name:   "default format with several files",
+			format: config.DefaultCommitFallbackFormat,
+			diff:   twoFiles,
+			want:   "chore: update 2 files",
+		},
+		{
+			name:   "custom format with every variable",
+			format: "chore: touch {{.Count}} files\n\n{{.Files}}",
+			diff:   twoFiles,
+			want:   "chore: touch 2 files\n\ninternal/git/diff.go, README.md",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := fallbackCommitMessage(&config.Config{CommitFallbackFormat: tt.format}, tt.diff)
+			if err != nil {
+				t.Fatalf("fallbackCommitMessage() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("fallbackCommitMessage() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFallbackCommitMessageUnknownVariable(t *testing.T) {
+	_, err := fallbackCommitMessage(&config.Config{CommitFallbackFormat: "chore: {{.Author}}"}, fileDiff("a.go"))
+	if err == nil {
+		t.Fatal("fallbackCommitMessage() succeeded with an unknown template variable")
+	}
+}
@@ -1,10 +1,13 @@
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"github.com/EkeMinusYou/gelf/internal/config"
+	"github.com/EkeMinusYou/gelf/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -21,9 +24,64 @@ var configListCmd = &cobra.Command{
 	RunE:  runConfigList,
 }
 
+var configExplainCmd = &cobra.Command{
+	Use:       "explain <key>",
+	Short:     "Explain how a configuration value is resolved",
+	Long:      "Show every source considered for a configuration key (flag, environment variable, config file, inherited key, default) and which one wins",
+	Args:      cobra.ExactArgs(1),
+	ValidArgs: config.ExplainableKeys(),
+	RunE:      runConfigExplain,
+}
+
+var configInitCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Create a commented gelf.yml",
+	Long:  "Write a commented gelf.yml to the XDG config directory (or --path), pre-filling the project ID from VERTEXAI_PROJECT or GOOGLE_CLOUD_PROJECT",
+	Args:  cobra.NoArgs,
+	RunE:  runConfigInit,
+}
+
+var configGetCmd = &cobra.Command{
+	Use:   "get <key>",
+	Short: "Print a configuration value",
+	Long:  "Print the value of a dotted key (e.g. commit.language) from the active gelf.yml, falling back to the effective value when the file does not set it",
+	Args:  cobra.ExactArgs(1),
+	RunE:  runConfigGet,
+}
+
+var configSetCmd = &cobra.Command{
+	Use:   "set <key> <value>",
+	Short: "Set a configuration value",
+	Long:  "Set a dotted key (e.g. vertex_ai.project_id) in the active gelf.yml, creating the file in the XDG config directory if none exists. Comments and other keys are preserved.",
+	Args:  cobra.ExactArgs(2),
+	RunE:  runConfigSet,
+}
+
+var configValidateCmd = &cobra.Command{
+	Use:          "validate",
+	Short:        "Check the configuration for mistakes",
+	Long:         "Report unknown keys, invalid values and missing required settings in the active gelf.yml and environment",
+	Args:         cobra.NoArgs,
+	SilenceUsage: true,
+	RunE:         runConfigValidate,
+}
+
+var (
+	configInitPath string
+	configListJSON bool
+)
+
 func init() {
 	rootCmd.AddCommand(configCmd)
 	configCmd.AddCommand(configListCmd)
+	configCmd.AddCommand(configExplainCmd)
+	configCmd.AddCommand(configInitCmd)
+	configCmd.AddCommand(configGetCmd)
+	configCmd.AddCommand(configSetCmd)
+	configCmd.AddCommand(configValidateCmd)
+
+	configListCmd.Flags().BoolVar(&configListJSON, "json", false, "Print the resolved configuration and the source of each value as JSON")
+	configInitCmd.Flags().StringVar(&configInitPath, "path", "", "Destination file (default: $XDG_CONFIG_HOME/gelf/gelf.yml or ~/.config/gelf/gelf.yml)")
 }
 
 func runConfigList(cmd *cobra.Command, args []string) error {
@@ -32,8 +90,13 @@ func runConfigList(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to load configuration: %w", err)
 	}
 
+	if configListJSON {
+		return printConfigJSON(cmd, cfg)
+	}
+
 	fmt.Println("Current Configuration:")
 	fmt.Println("======================")
+	fmt.Printf("Backend:           %s\n", cfg.Backend)
 	fmt.Printf("Project ID:        %s\n", cfg.ProjectID)
 	fmt.Printf("Location:          %s\n", cfg.Location)
 	fmt.Printf("Flash Model:       %s\n", cfg.FlashModel)
@@ -50,6 +113,8 @@ func runConfigList(cmd *cobra.Command, args []string) error {
 	printEnvVar("VERTEXAI_LOCATION")
 	printEnvVar("GELF_CREDENTIALS")
 	printEnvVar("GOOGLE_APPLICATION_CREDENTIALS")
+	printSecretEnvVar("GELF_API_KEY")
+	printSecretEnvVar("GEMINI_API_KEY")
 
 	return nil
 }
@@ -62,3 +127,200 @@ func printEnvVar(name string) {
 		fmt.Printf("%-30s (not set)\n", name+":")
 	}
 }
+
+type configValueJSON struct {
+	Value  any    `json:"value"`
+	Source string `json:"source"`
+	Origin string `json:"origin,omitempty"`
+}
+
+type configListOutput struct {
+	ConfigFile string                     `json:"config_file,omitempty"`
+	Values     map[string]configValueJSON `json:"values"`
+}
+
+// printConfigJSON prints every key of cfg with its typed value and the
+// source it came from (env, file, inherit, default, or unset).
+func printConfigJSON(cmd *cobra.Command, cfg *config.Config) error {
+	path, _ := config.FindConfigFile()
+
+	output := configListOutput{
+		ConfigFile: path,
+		Values:     make(map[string]configValueJSON, len(cfg.Resolutions)),
+	}
+	for _, res := range cfg.Resolutions {
+		value := configValueJSON{Value: res.Value, Source: "unset"}
+		if source := res.WinningSource(); source != nil {
+			value.Source = source.Kind
+			value.Origin = source.Name
+		}
+		output.Values[res.Key] = value
+	}
+
+	encoder := json.NewEncoder(cmd.OutOrStdout())
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(output)
+}
+
+// printSecretEnvVar reports whether a secret environment variable is set
+// without printing its value.
+func printSecretEnvVar(name string) {
+	if os.Getenv(name) != "" {
+		fmt.Printf("%-30s (set)\n", name+":")
+	} else {
+		fmt.Printf("%-30s (not set)\n", name+":")
+	}
+}
+
+func runConfigExplain(cmd *cobra.Command, args []string) error {
+	res, err := config.Explain(args[0])
+	if err != nil {
+		return err
+	}
+
+	value := config.FormatValue(res.Value)
+	if value == "" {
+		value = "(empty)"
+	}
+	fmt.Printf("Key:   %s\n", res.Key)
+	fmt.Printf("Value: %s\n", value)
+
+	fmt.Println("\nSources (highest priority first):")
+	for i, source := range res.Sources {
+		marker := " "
+		if i == res.Winner {
+			marker = "✓"
+		}
+
+		detail := config.FormatValue(source.Value)
+		switch {
+		case source.Kind == "flag":
+			detail = "(only for that command)"
+		case !source.Set:
+			detail = "(not set)"
+		}
+		fmt.Printf("%s %-8s %-45s %s\n", marker, source.Kind, source.Name, detail)
+	}
+
+	fmt.Printf("\nWhy: %s\n", res.Reason)
+	return nil
+}
+
+func runConfigInit(cmd *cobra.Command, args []string) error {
+	path := configInitPath
+	if path == "" {
+		var err error
+		path, err = config.UserConfigPath()
+		if err != nil {
+			return fmt.Errorf("failed to determine config directory: %w", err)
+		}
+	}
+
+	projectID := os.Getenv("VERTEXAI_PROJECT")
+	if projectID == "" {
+		projectID = os.Getenv("GOOGLE_CLOUD_PROJECT")
+	}
+	content := config.Scaffold(config.ScaffoldOptions{
+		ProjectID: projectID,
+		Location:  os.Getenv("VERTEXAI_LOCATION"),
+	})
+
+	written, err := writeConfigFile(cmd, path, content)
+	if err != nil {
+		return err
+	}
+	if written {
+		fmt.Printf("✅ Wrote %s\n", path)
+	}
+	return nil
+}
+
+// writeConfigFile writes content to path, creating parent directories and
+// asking before overwriting an existing file. It reports whether the file was
+// written.
+func writeConfigFile(cmd *cobra.Command, path, content string) (bool, error) {
+	if _, err := os.Stat(path); err == nil {
+		confirmed, err := ui.PromptYesNoStyledWithWriter(fmt.Sprintf("%s already exists. Overwrite? (y)es / (n)o", path), cmd.ErrOrStderr())
+		if err != nil {
+			return false, err
+		}
+		if !confirmed {
+			fmt.Fprintln(cmd.ErrOrStderr(), "Aborted; existing configuration left unchanged.")
+			return false, nil
+		}
+	}
+
+	if err := writeFileWithParents(path, content); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+func runConfigGet(cmd *cobra.Command, args []string) error {
+	key := args[0]
+
+	if path, ok := config.FindConfigFile(); ok {
+		value, found, err := config.GetFileValue(path, key)
+		if err != nil {
+			return err
+		}
+		if found {
+			fmt.Println(value)
+			return nil
+		}
+	}
+
+	// Not in the file: report the effective value when it is known
+	if res, err := config.Explain(key); err == nil {
+		fmt.Println(config.FormatValue(res.Value))
+		return nil
+	}
+	return fmt.Errorf("%s is not set", key)
+}
+
+func runConfigSet(cmd *cobra.Command, args []string) error {
+	key, value := args[0], args[1]
+
+	path, ok := config.FindConfigFile()
+	if !ok {
+		var err error
+		path, err = config.UserConfigPath()
+		if err != nil {
+			return fmt.Errorf("failed to determine config directory: %w", err)
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+		}
+	}
+
+	if err := config.SetFileValue(path, key, value); err != nil {
+		return err
+	}
+	fmt.Printf("Set %s = %s in %s\n", key, value, path)
+	return nil
+}
+
+func runConfigValidate(cmd *cobra.Command, args []string) error {
+	path, problems := config.Validate()
+
+	source := path
+	if source == "" {
+		source = "configuration (no config file found)"
+	}
+
+	errorCount := 0
+	for _, problem := range problems {
+		if problem.Warning {
+			fmt.Printf("⚠️  %s\n", problem.Message)
+			continue
+		}
+		errorCount++
+		fmt.Printf("❌ %s\n", problem.Message)
+	}
+
+	if errorCount > 0 {
+		return fmt.Errorf("%s has %d error(s)", source, errorCount)
+	}
+	fmt.Printf("✅ %s is valid\n", source)
+	return nil
+}
@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/EkeMinusYou/gelf/internal/git"
+	"github.com/spf13/cobra"
+)
+
+// hookMarker identifies hooks written by gelf, so uninstall never removes a
+// hook it did not install.
+const hookMarker = "# Installed by gelf (gelf hooks install)"
+
+// gitHooks are the hooks gelf installs.
+var gitHooks = []struct {
+	name   string
+	script string
+}{
+	// Prepend a generated message for plain `git commit` only; messages
+	// from -m, templates, merges, squashes and amends are left alone.
+	// --show-final applies the ticket reference and sign-off like gelf
+	// commit does. Failures never block the commit.
+	{"prepare-commit-msg", `#!/bin/sh
+` + hookMarker + `
+[ -n "$2" ] && exit 0
+message=$(git diff --cached | gelf commit --dry-run --stdin --quiet --show-final 2>/dev/null) || exit 0
+[ -n "$message" ] || exit 0
+{ printf '%s\n' "$message"; cat "$1"; } > "$1.gelf" && mv "$1.gelf" "$1"
+`},
+	// Reject messages that are empty or break Conventional Commits.
+	{"commit-msg", `#!/bin/sh
+` + hookMarker + `
+exec gelf lint-message --stdin < "$1"
+`},
+}
+
+var hooksCmd = &cobra.Command{
+	Use:   "hooks",
+	Short: "Manage gelf git hooks",
+	Long:  "Install or remove git hooks that generate commit messages with gelf during `git commit`",
+}
+
+var hooksInstallCmd = &cobra.Command{
+	Use:   "install",
+	Short: "Install the prepare-commit-msg and commit-msg hooks",
+	Long:  "Write a prepare-commit-msg hook that pre-fills the message with `gelf commit --dry-run --stdin`, and a commit-msg hook that checks it with `gelf lint-message`, into the repository's hooks directory",
+	Args:  cobra.NoArgs,
+	RunE:  runHooksInstall,
+}
+
+var hooksUninstallCmd = &cobra.Command{
+	Use:   "uninstall",
+	Short: "Remove the hooks installed by gelf",
+	Long:  "Remove the prepare-commit-msg and commit-msg hooks written by `gelf hooks install`; hooks gelf did not write are left alone",
+	Args:  cobra.NoArgs,
+	RunE:  runHooksUninstall,
+}
+
+var hooksForce bool
+
+func init() {
+	rootCmd.AddCommand(hooksCmd)
+	hooksCmd.AddCommand(hooksInstallCmd)
+	hooksCmd.AddCommand(hooksUninstallCmd)
+
+	hooksInstallCmd.Flags().BoolVar(&hooksForce, "force", false, "Overwrite existing hooks")
+}
+
+func runHooksInstall(cmd *cobra.Command, args []string) error {
+	dir, err := hooksDir()
+	if err != nil {
+		return err
+	}
+
+	// Check every hook first so nothing is half-installed
+	if !hooksForce {
+		for _, hook := range gitHooks {
+			path := filepath.Join(dir, hook.name)
+			if _, err := os.Stat(path); err == nil && !isGelfHook(path) {
+				return fmt.Errorf("%s already exists (use --force to overwrite)", path)
+			}
+		}
+	}
+
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	for _, hook := range gitHooks {
+		path := filepath.Join(dir, hook.name)
+		if err := os.WriteFile(path, []byte(hook.script), 0o755); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		// WriteFile keeps the mode of an existing file
+		if err := os.Chmod(path, 0o755); err != nil {
+			return fmt.Errorf("failed to make %s executable: %w", path, err)
+		}
+		fmt.Printf("Installed %s\n", path)
+	}
+	return nil
+}
+
+func runHooksUninstall(cmd *cobra.Command, args []string) error {
+	dir, err := hooksDir()
+	if err != nil {
+		return err
+	}
+
+	removed := 0
+	for _, hook := range gitHooks {
+		path := filepath.Join(dir, hook.name)
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		if !isGelfHook(path) {
+			fmt.Printf("Skipped %s (not installed by gelf)\n", path)
+			continue
+		}
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove %s: %w", path, err)
+		}
+		fmt.Printf("Removed %s\n", path)
+		removed++
+	}
+
+	if removed == 0 {
+		fmt.Println("No gelf hooks installed.")
+	}
+	return nil
+}
+
+func hooksDir() (string, error) {
+	if err := git.EnsureRepo(); err != nil {
+		return "", err
+	}
+	return git.GetHooksDir()
+}
+
+func isGelfHook(path string) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false
+	}
+	return strings.Contains(string(data), hookMarker)
+}
@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/EkeMinusYou/gelf/internal/ai"
+	"github.com/EkeMinusYou/gelf/internal/config"
+	"github.com/EkeMinusYou/gelf/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var initCmd = &cobra.Command{
+	Use:   "init",
+	Short: "Set up gelf interactively",
+	Long: `Walks through the Vertex AI project, location, credentials, language and models,
+checks that the credentials and commit model work, and writes gelf.yml.`,
+	Args: cobra.NoArgs,
+	RunE: runInit,
+}
+
+var (
+	initNonInteractive bool
+	initSkipVerify     bool
+	initForce          bool
+	initPath           string
+	initProjectID      string
+	initLocation       string
+	initLanguage       string
+	initFlashModel     string
+	initProModel       string
+)
+
+func init() {
+	rootCmd.AddCommand(initCmd)
+	initCmd.Flags().BoolVar(&initNonInteractive, "non-interactive", false, "Don't prompt; use flags, environment variables and defaults")
+	initCmd.Flags().BoolVar(&initSkipVerify, "skip-verify", false, "Don't check credentials or ping the model")
+	initCmd.Flags().BoolVar(&initForce, "force", false, "Overwrite an existing config file without asking")
+	initCmd.Flags().StringVar(&initPath, "path", "", "Destination file (default: $XDG_CONFIG_HOME/gelf/gelf.yml or ~/.config/gelf/gelf.yml)")
+	initCmd.Flags().StringVar(&initProjectID, "project-id", "", "Google Cloud project ID")
+	initCmd.Flags().StringVar(&initLocation, "location", "", "Vertex AI location (default: global)")
+	initCmd.Flags().StringVar(&initLanguage, "language", "", "Default language for generated text (default: english)")
+	initCmd.Flags().StringVar(&initFlashModel, "flash-model", "", "Model used as \"flash\" (default: "+config.DefaultFlashModel+")")
+	initCmd.Flags().StringVar(&initProModel, "pro-model", "", "Model used as \"pro\" (default: "+config.DefaultProModel+")")
+}
+
+func runInit(cmd *cobra.Command, args []string) error {
+	ctx, cancel := context.WithTimeout(cmd.Context(), 2*time.Minute)
+	defer cancel()
+
+	out := cmd.OutOrStdout()
+	reader := bufio.NewReader(cmd.InOrStdin())
+
+	path := initPath
+	if path == "" {
+		var err error
+		path, err = config.UserConfigPath()
+		if err != nil {
+			return fmt.Errorf("failed to determine config directory: %w", err)
+		}
+	}
+
+	if _, err := os.Stat(path); err == nil && !initForce {
+		if initNonInteractive {
+			return fmt.Errorf("%s already exists (use --force to overwrite)", path)
+		}
+		answer, err := ui.PromptString(reader, out, fmt.Sprintf("%s already exists. Overwrite? (y/N)", path), "")
+		if err != nil {
+			return err
+		}
+		if !strings.HasPrefix(strings.ToLower(answer), "y") {
+			fmt.Fprintln(out, "Aborted; existing configuration left unchanged.")
+			return nil
+		}
+	}
+
+	opts := config.ScaffoldOptions{
+		ProjectID:  firstNonEmpty(initProjectID, os.Getenv("VERTEXAI_PROJECT"), os.Getenv("GOOGLE_CLOUD_PROJECT")),
+		Location:   firstNonEmpty(initLocation, os.Getenv("VERTEXAI_LOCATION"), "global"),
+		Language:   firstNonEmpty(initLanguage, "english"),
+		FlashModel: firstNonEmpty(initFlashModel, config.DefaultFlashModel),
+		ProModel:   firstNonEmpty(initProModel, config.DefaultProModel),
+	}
+
+	if !initNonInteractive {
+		if err := askInitOptions(reader, out, &opts); err != nil {
+			return err
+		}
+	}
+	if opts.ProjectID == "" {
+		return fmt.Errorf("a Google Cloud project ID is required (--project-id or VERTEXAI_PROJECT)")
+	}
+
+	if !initSkipVerify {
+		if err := verifySetup(ctx, out, opts); err != nil {
+			return err
+		}
+	}
+
+	if err := writeFileWithParents(path, config.Scaffold(opts)); err != nil {
+		return err
+	}
+	fmt.Fprintf(out, "✅ Wrote %s\n", path)
+	return nil
+}
+
+// askInitOptions prompts for each setting, offering the current value as
+// the default.
+func askInitOptions(reader *bufio.Reader, out io.Writer, opts *config.ScaffoldOptions) error {
+	questions := []struct {
+		prompt string
+		value  *string
+	}{
+		{"Google Cloud project ID", &opts.ProjectID},
+		{"Vertex AI location", &opts.Location},
+		{"Default language", &opts.Language},
+		{"Flash model", &opts.FlashModel},
+		{"Pro model", &opts.ProModel},
+	}
+
+	for i, q := range questions {
+		answer, err := ui.PromptString(reader, out, q.prompt, *q.value)
+		if err != nil {
+			return err
+		}
+		*q.value = answer
+
+		if i == 0 {
+			printCredentialGuidance(out)
+		}
+	}
+	return nil
+}
+
+func printCredentialGuidance(out io.Writer) {
+	switch {
+	case os.Getenv("GELF_CREDENTIALS") != "":
+		fmt.Fprintf(out, "  Using credentials from GELF_CREDENTIALS (%s)\n", os.Getenv("GELF_CREDENTIALS"))
+	case os.Getenv("GOOGLE_APPLICATION_CREDENTIALS") != "":
+		fmt.Fprintf(out, "  Using credentials from GOOGLE_APPLICATION_CREDENTIALS (%s)\n", os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"))
+	default:
+		fmt.Fprintln(out, "  No credentials file configured; gelf will use Application Default Credentials.")
+		fmt.Fprintln(out, "  Run 'gcloud auth application-default login' or set GELF_CREDENTIALS to a service account key.")
+	}
+}
+
+// verifySetup checks that credentials are available and that the flash
+// model answers a minimal request.
+func verifySetup(ctx context.Context, out io.Writer, opts config.ScaffoldOptions) error {
+	cfg := &config.Config{
+		ProjectID:  opts.ProjectID,
+		Location:   opts.Location,
+		FlashModel: opts.FlashModel,
+		ProModel:   opts.ProModel,
+		AITimeout:  30 * time.Second,
+	}
+
+	fmt.Fprint(out, "Checking credentials... ")
+	aiClient, err := ai.NewVertexAIClient(ctx, cfg)
+	if err != nil {
+		fmt.Fprintln(out, "failed")
+		return fmt.Errorf("credential check failed: %w (use --skip-verify to write the config anyway)", err)
+	}
+	fmt.Fprintln(out, "ok")
+
+	fmt.Fprintf(out, "Pinging %s... ", opts.FlashModel)
+	if err := aiClient.Ping(ctx); err != nil {
+		fmt.Fprintln(out, "failed")
+		return fmt.Errorf("model check failed: %w (use --skip-verify to write the config anyway)", err)
+	}
+	fmt.Fprintln(out, "ok")
+	return nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"bufio"
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/EkeMinusYou/gelf/internal/config"
+	"gopkg.in/yaml.v3"
+)
+
+func decodeScaffold(t *testing.T, content string) config.FileConfig {
+	t.Helper()
+	var fileConfig config.FileConfig
+	if err := yaml.Unmarshal([]byte(content), &fileConfig); err != nil {
+		t.Fatalf("scaffold is not valid YAML: %v\n%s", err, content)
+	}
+	return fileConfig
+}
+
+func TestAskInitOptions(t *testing.T) {
+	t.Setenv("GELF_CREDENTIALS", "")
+	t.Setenv("GOOGLE_APPLICATION_CREDENTIALS", "")
+
+	opts := config.ScaffoldOptions{
+		ProjectID:  "env-project",
+		Location:   "global",
+		Language:   "english",
+		FlashModel: config.DefaultFlashModel,
+		ProModel:   config.DefaultProModel,
+	}
+	// Empty answers keep the offered default
+	answers := "my-project\nus-central1\njapanese\n\ngemini-custom-pro\n"
+	var out bytes.Buffer
+	if err := askInitOptions(bufio.NewReader(strings.NewReader(answers)), &out, &opts); err != nil {
+		t.Fatalf("askInitOptions() error = %v", err)
+	}
+
+	fileConfig := decodeScaffold(t, config.Scaffold(opts))
+	if got := fileConfig.VertexAI.ProjectID; got != "my-project" {
+		t.Errorf("vertex_ai.project_id = %q, want my-project", got)
+	}
+	if got := fileConfig.VertexAI.Location; got != "us-central1" {
+		t.Errorf("vertex_ai.location = %q, want us-central1", got)
+	}
+	if got := fileConfig.Language; got != "japanese" {
+		t.Errorf("language = %q, want japanese", got)
+	}
+	if got := fileConfig.Model.Flash; got != config.DefaultFlashModel {
+		t.Errorf("model.flash = %q, want the default %q", got, config.DefaultFlashModel)
+	}
+	if got := fileConfig.Model.Pro; got != "gemini-custom-pro" {
+		t.Errorf("model.pro = %q, want gemini-custom-pro", got)
+	}
+
+	if !strings.Contains(out.String(), "Google Cloud project ID [env-project]:") {
+		t.Errorf("prompt does not offer the current project ID:\n%s", out.String())
+	}
+	if !strings.Contains(out.String(), "Application Default Credentials") {
+		t.Errorf("credential guidance is missing:\n%s", out.String())
+	}
+}
+
+func TestInitNonInteractive(t *testing.T) {
+	home := t.TempDir()
+	t.Chdir(t.TempDir())
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("VERTEXAI_PROJECT", "")
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "")
+	t.Setenv("VERTEXAI_LOCATION", "")
+
+	path := filepath.Join(t.TempDir(), "gelf.yml")
+	rootCmd.SetArgs([]string{"init", "--non-interactive", "--skip-verify", "--path", path, "--project-id", "ci-project", "--language", "german"})
+	rootCmd.SetIn(strings.NewReader(""))
+	rootCmd.SetOut(&bytes.Buffer{})
+	defer rootCmd.SetArgs(nil)
+
+	if err := rootCmd.Execute(); err != nil {
+		t.Fatalf("gelf init error = %v", err)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileConfig := decodeScaffold(t, string(data))
+	if got := fileConfig.VertexAI.ProjectID; got != "ci-project" {
+		t.Errorf("vertex_ai.project_id = %q, want ci-project", got)
+	}
+	if got := fileConfig.VertexAI.Location; got != "global" {
+		t.Errorf("vertex_ai.location = %q, want global", got)
+	}
+	if got := fileConfig.Language; got != "german" {
+		t.Errorf("language = %q, want german", got)
+	}
+	if got := fileConfig.Model.Flash; got != config.DefaultFlashModel {
+		t.Errorf("model.flash = %q, want %q", got, config.DefaultFlashModel)
+	}
+}
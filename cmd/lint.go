@@ -0,0 +1,76 @@
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/EkeMinusYou/gelf/internal/config"
+	"github.com/EkeMinusYou/gelf/internal/git"
+	"github.com/EkeMinusYou/gelf/internal/ui"
+	"github.com/spf13/cobra"
+)
+
+var lintMessageCmd = &cobra.Command{
+	Use:   "lint-message [file]",
+	Short: "Check a commit message against Conventional Commits",
+	Long: `Checks a commit message (from a file such as .git/COMMIT_EDITMSG, or from --stdin)
+against the Conventional Commits rules gelf generates for, printing each rule violated.
+Comment lines are ignored, so it can be used directly from a commit-msg hook.
+The check is skipped when commit.prompt_template is set, since custom prompts
+may not ask for Conventional Commits.`,
+	Args:         cobra.MaximumNArgs(1),
+	SilenceUsage: true,
+	RunE:         runLintMessage,
+}
+
+var lintFromStdin bool
+
+func init() {
+	rootCmd.AddCommand(lintMessageCmd)
+	lintMessageCmd.Flags().BoolVar(&lintFromStdin, "stdin", false, "Read the message from standard input")
+}
+
+func runLintMessage(cmd *cobra.Command, args []string) error {
+	var data []byte
+	var err error
+	switch {
+	case lintFromStdin && len(args) > 0:
+		return fmt.Errorf("pass either a file or --stdin, not both")
+	case lintFromStdin:
+		data, err = io.ReadAll(os.Stdin)
+	case len(args) == 1:
+		data, err = os.ReadFile(args[0])
+	default:
+		return fmt.Errorf("pass a message file or --stdin")
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read commit message: %w", err)
+	}
+
+	cfg, err := config.Load()
+	if err != nil {
+		return fmt.Errorf("failed to load configuration: %w", err)
+	}
+
+	if cfg.CommitPromptTemplate != "" {
+		// Same rule as the commit TUI's warnings
+		fmt.Fprintln(cmd.ErrOrStderr(), "commit.prompt_template is set; skipping the Conventional Commits check")
+		return nil
+	}
+
+	if err := git.ValidateConventionalCommit(git.StripComments(string(data)), cfg.CommitTypes); err != nil {
+		printLintProblems(cmd.ErrOrStderr(), err)
+		return fmt.Errorf("commit message does not follow Conventional Commits")
+	}
+	return nil
+}
+
+// printLintProblems prints one line per rule reported by
+// git.ValidateConventionalCommit.
+func printLintProblems(out io.Writer, err error) {
+	for _, problem := range strings.Split(err.Error(), "\n") {
+		fmt.Fprintln(out, ui.RenderWarning("⚠ "+problem))
+	}
+}
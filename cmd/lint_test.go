@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLintMessagePromptTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		config   string
+		message  string
+		wantErr  bool
+		wantNote bool
+	}{
+		{
+			name:    "valid message",
+			message: "feat(api): add pagination\n",
+		},
+		{
+			name:    "invalid message",
+			message: "Added pagination.\n",
+			wantErr: true,
+		},
+		{
+			name:     "custom prompt template skips the check",
+			config:   "commit:\n  prompt_template: \"Write a commit message for:\\n{{.Diff}}\"\n",
+			message:  "Added pagination.\n",
+			wantNote: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			home := t.TempDir()
+			t.Chdir(t.TempDir())
+			t.Setenv("HOME", home)
+			t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+			t.Setenv("VERTEXAI_PROJECT", "p")
+			if err := os.WriteFile("gelf.yml", []byte(tt.config), 0o644); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile("COMMIT_EDITMSG", []byte(tt.message), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			var stderr bytes.Buffer
+			rootCmd.SetArgs([]string{"lint-message", "COMMIT_EDITMSG"})
+			rootCmd.SetErr(&stderr)
+			rootCmd.SetOut(&bytes.Buffer{})
+			defer rootCmd.SetArgs(nil)
+			defer rootCmd.SetErr(nil)
+
+			err := rootCmd.Execute()
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("gelf lint-message error = %v, wantErr %v\n%s", err, tt.wantErr, stderr.String())
+			}
+			if got := strings.Contains(stderr.String(), "skipping the Conventional Commits check"); got != tt.wantNote {
+				t.Errorf("skip note printed = %v, want %v:\n%s", got, tt.wantNote, stderr.String())
+			}
+		})
+	}
+}
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"io"
 	"net/url"
+	"os"
 	"os/exec"
+	"os/signal"
 	"regexp"
 	"strconv"
 	"strings"
@@ -41,6 +43,7 @@ var (
 	prNoRender      bool
 	prYes           bool
 	prUpdate        bool
+	prNoPrelude     bool
 )
 
 func init() {
@@ -54,12 +57,19 @@ func init() {
 	prCreateCmd.Flags().BoolVar(&prNoRender, "no-render", false, "Disable markdown rendering in dry-run output")
 	prCreateCmd.Flags().BoolVar(&prYes, "yes", false, "Automatically approve PR creation without confirmation")
 	prCreateCmd.Flags().BoolVar(&prUpdate, "update", false, "Update existing pull request when one already exists")
+	prCreateCmd.Flags().BoolVar(&prNoPrelude, "no-prelude", false, "Don't include the repository prompt prelude (.gelf/prompt.md)")
 
 	prCmd.AddCommand(prCreateCmd)
 }
 
 func runPRCreate(cmd *cobra.Command, args []string) error {
-	ctx := context.Background()
+	// Cancel in-flight AI requests on Ctrl+C outside the TUI
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if err := git.EnsureRepo(); err != nil {
+		return err
+	}
 
 	cfg, err := config.Load()
 	if err != nil {
@@ -83,9 +93,7 @@ func runPRCreate(cmd *cobra.Command, args []string) error {
 		prRender = false
 	}
 
-	if !cfg.UseColor() {
-		ui.DisableColor()
-	}
+	ui.SetMaxSummaryFiles(cfg.UIMaxSummaryFiles)
 
 	modelToUse := cfg.PRModel
 	if prModel != "" {
@@ -204,12 +212,13 @@ func runPRCreate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("no commits found between %s and %s", baseRef, headBranch)
 	}
 
-	diffStat, err := git.GetCommittedDiffStat(baseRef, "HEAD")
+	diffOptions := git.DiffOptions{ContextLines: cfg.DiffContextLines, Exclude: cfg.DiffExclude}
+	diffStat, err := git.GetCommittedDiffStat(baseRef, "HEAD", diffOptions)
 	if err != nil {
 		return fmt.Errorf("failed to get diff stat: %w", err)
 	}
 
-	diff, err := git.GetCommittedDiff(baseRef, "HEAD")
+	diff, err := git.GetCommittedDiff(baseRef, "HEAD", diffOptions)
 	if err != nil {
 		return fmt.Errorf("failed to get diff: %w", err)
 	}
@@ -222,6 +231,12 @@ func runPRCreate(cmd *cobra.Command, args []string) error {
 		return fmt.Errorf("failed to create AI client: %w", err)
 	}
 
+	if !prNoPrelude {
+		if err := aiClient.UseRepoPromptPrelude(); err != nil {
+			return err
+		}
+	}
+
 	templateContent := ""
 	templatePath := ""
 	templateSource := ""
@@ -287,7 +302,7 @@ func runPRCreate(cmd *cobra.Command, args []string) error {
 		if updateExisting {
 			confirmPrompt = "Update this pull request? (y)es / (n)o"
 		}
-		prTUI := ui.NewPRTUI(aiClient, ai.PullRequestInput{
+		prTUI := ui.NewPRTUI(ctx, aiClient, ai.PullRequestInput{
 			BaseBranch:    baseBranch,
 			HeadBranch:    headBranch,
 			CommitLog:     commitLog,
@@ -4,8 +4,11 @@ import (
 	"fmt"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 
+	"github.com/EkeMinusYou/gelf/internal/config"
+	"github.com/EkeMinusYou/gelf/internal/ui"
 	"github.com/spf13/cobra"
 )
 
@@ -13,8 +16,9 @@ import (
 var version = "dev"
 
 var rootCmd = &cobra.Command{
-	Use:   "gelf",
-	Short: "AI-powered Git commit message generator using Vertex AI (Gemini)",
+	Use:              "gelf",
+	PersistentPreRun: applyColorSetting,
+	Short:            "AI-powered Git commit message generator using Vertex AI (Gemini)",
 	Long: `gelf is a CLI tool that generates Git commit messages using Vertex AI (Gemini).
 It analyzes staged changes and creates appropriate commit messages through an interactive TUI.`,
 }
@@ -45,6 +49,30 @@ func getGitCommitHash() string {
 	return strings.TrimSpace(string(output))
 }
 
+// applyColorSetting turns off styled output for every command when color is
+// disabled by the color setting, NO_COLOR or a non-terminal stdout.
+func applyColorSetting(cmd *cobra.Command, args []string) {
+	cfg, err := config.Load()
+	if err != nil {
+		// The command reports the error itself; decide from the environment
+		cfg = &config.Config{Color: "auto"}
+	}
+	if !cfg.UseColor() {
+		ui.DisableColor()
+	}
+}
+
+// writeFileWithParents writes content to path, creating parent directories.
+func writeFileWithParents(path, content string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return nil
+}
+
 func Execute() error {
 	return rootCmd.Execute()
 }
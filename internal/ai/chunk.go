@@ -0,0 +1,94 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/EkeMinusYou/gelf/internal/git"
+	"google.golang.org/genai"
+)
+
+// charsPerToken approximates the tokenizer so diff sizes can be checked
+// without a CountTokens round trip.
+const charsPerToken = 4
+
+func estimateTokens(text string) int {
+	return len(text) / charsPerToken
+}
+
+// condenseLargeDiff replaces a diff larger than commit.max_diff_tokens with
+// per-chunk summaries, so the final prompt fits the model's context window.
+// Diffs within the limit are returned unchanged.
+func (v *VertexAIClient) condenseLargeDiff(ctx context.Context, input CommitMessageInput) (CommitMessageInput, error) {
+	if v.maxDiffTokens <= 0 || estimateTokens(input.Diff) <= v.maxDiffTokens {
+		return input, nil
+	}
+
+	chunks := chunkDiff(input.Diff, v.maxDiffTokens)
+	summaries := make([]string, len(chunks))
+	for i, chunk := range chunks {
+		summary, err := v.summarizeDiffChunk(ctx, chunk)
+		if err != nil {
+			return input, fmt.Errorf("failed to summarize diff part %d of %d: %w", i+1, len(chunks), err)
+		}
+		summaries[i] = fmt.Sprintf("### Part %d of %d\n%s", i+1, len(chunks), summary)
+	}
+
+	input.Diff = fmt.Sprintf(`NOTE: The staged diff was too large to include, so it was split into %d parts and each part was summarized. Write the commit message from these summaries as if they were the diff.
+
+%s`, len(chunks), strings.Join(summaries, "\n\n"))
+	return input, nil
+}
+
+// chunkDiff groups the files of diff into chunks of at most maxTokens
+// (estimated). A single file over the limit is truncated to fit.
+func chunkDiff(diff string, maxTokens int) []string {
+	maxChars := maxTokens * charsPerToken
+
+	var chunks []string
+	var current strings.Builder
+	for _, file := range git.SplitDiff(diff) {
+		if len(file) > maxChars {
+			file = strings.ToValidUTF8(file[:maxChars], "") + "\n[truncated]"
+		}
+		if current.Len() > 0 && current.Len()+len(file)+1 > maxChars {
+			chunks = append(chunks, current.String())
+			current.Reset()
+		}
+		if current.Len() > 0 {
+			current.WriteString("\n")
+		}
+		current.WriteString(file)
+	}
+	if current.Len() > 0 {
+		chunks = append(chunks, current.String())
+	}
+	return chunks
+}
+
+func (v *VertexAIClient) summarizeDiffChunk(ctx context.Context, chunk string) (string, error) {
+	prompt := fmt.Sprintf(`You are summarizing one part of a large staged git diff so that a commit message can be written for the whole change.
+
+Describe what changed in this part in at most 8 concise bullet points. Name the files or components involved, focus on behavior and intent rather than line-by-line edits, and call out breaking changes. Use English and output only the bullet points.
+
+Diff part:
+%s`, git.RedactDiff(chunk, v.redactPaths))
+
+	resp, err := v.generateContent(ctx, v.flashModel,
+		[]*genai.Content{
+			genai.NewContentFromText(prompt, genai.RoleUser),
+		},
+		&genai.GenerateContentConfig{
+			Temperature: genai.Ptr(float32(0.2)),
+		})
+	if err != nil {
+		return "", err
+	}
+
+	text := strings.TrimSpace(responseText(resp))
+	if text == "" {
+		return "", fmt.Errorf("empty text in response")
+	}
+	return text, nil
+}
@@ -0,0 +1,165 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"strings"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+// maxRetryDelay caps the exponential backoff between retries, unless
+// ai.retry_base_delay is longer.
+const maxRetryDelay = 30 * time.Second
+
+// retryableStatuses are the gRPC status names Vertex AI reports for
+// transient failures.
+var retryableStatuses = map[string]bool{
+	"RESOURCE_EXHAUSTED": true,
+	"UNAVAILABLE":        true,
+	"DEADLINE_EXCEEDED":  true,
+	"INTERNAL":           true,
+}
+
+func isRetryable(err error) bool {
+	var apiErr genai.APIError
+	if !errors.As(err, &apiErr) {
+		return false
+	}
+
+	switch apiErr.Code {
+	case http.StatusTooManyRequests, http.StatusInternalServerError, http.StatusBadGateway,
+		http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return retryableStatuses[apiErr.Status]
+}
+
+// generateContent calls the model, retrying transient errors with
+// exponential backoff and jitter until maxRetries is exhausted or ctx is done.
+// Each attempt is bounded by the configured ai.timeout.
+func (v *VertexAIClient) generateContent(ctx context.Context, model string, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	var resp *genai.GenerateContentResponse
+	err := v.withRetry(ctx, func() error {
+		var err error
+		resp, err = v.generateContentOnce(ctx, model, contents, config)
+		return err
+	})
+	return resp, err
+}
+
+// streamContent streams the model's response, calling onText with each
+// chunk of text. Transient errors are retried like generateContent as long as
+// no text has been delivered yet.
+func (v *VertexAIClient) streamContent(ctx context.Context, model string, contents []*genai.Content, config *genai.GenerateContentConfig, onText func(string)) error {
+	return v.withRetry(ctx, func() error {
+		return v.streamContentOnce(ctx, model, contents, config, onText)
+	})
+}
+
+// withRetry runs attempt until it succeeds, fails with a non-retryable
+// error, maxRetries is exhausted or ctx is done.
+func (v *VertexAIClient) withRetry(ctx context.Context, attemptFn func() error) error {
+	var lastErr error
+	for attempt := 0; ; attempt++ {
+		err := attemptFn()
+		if err == nil {
+			return nil
+		}
+		lastErr = err
+		if ctx.Err() != nil {
+			return err
+		}
+
+		if !isRetryable(err) || attempt >= v.maxRetries {
+			break
+		}
+
+		// Full jitter: sleep a random duration up to base * 2^attempt
+		delay := time.Duration(rand.Int64N(int64(retryBackoff(v.retryBaseDelay, attempt)) + 1))
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return fmt.Errorf("%w (after %d retries: %v)", ctx.Err(), attempt, lastErr)
+		case <-timer.C:
+		}
+	}
+
+	if v.maxRetries > 0 && isRetryable(lastErr) {
+		return fmt.Errorf("%w (gave up after %d retries)", lastErr, v.maxRetries)
+	}
+	return lastErr
+}
+
+// retryBackoff returns base * 2^attempt, capped at maxRetryDelay (or base
+// when that is longer) without overflowing.
+func retryBackoff(base time.Duration, attempt int) time.Duration {
+	limit := max(base, maxRetryDelay)
+	backoff := base
+	for i := 0; i < attempt && backoff < limit; i++ {
+		backoff *= 2
+	}
+	return min(backoff, limit)
+}
+
+func (v *VertexAIClient) generateContentOnce(ctx context.Context, model string, contents []*genai.Content, config *genai.GenerateContentConfig) (*genai.GenerateContentResponse, error) {
+	if v.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, v.timeout)
+		defer cancel()
+	}
+
+	resp, err := v.client.Models.GenerateContent(ctx, model, contents, config)
+	if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, fmt.Errorf("request timed out after %s: %w", v.timeout, err)
+	}
+	return resp, err
+}
+
+func (v *VertexAIClient) streamContentOnce(ctx context.Context, model string, contents []*genai.Content, config *genai.GenerateContentConfig, onText func(string)) error {
+	if v.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, v.timeout)
+		defer cancel()
+	}
+
+	received := false
+	for resp, err := range v.client.Models.GenerateContentStream(ctx, model, contents, config) {
+		if err != nil {
+			if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				err = fmt.Errorf("request timed out after %s: %w", v.timeout, err)
+			}
+			if received {
+				// Part of the response was already shown, so don't retry
+				return fmt.Errorf("stream interrupted: %v", err)
+			}
+			return err
+		}
+
+		if text := responseText(resp); text != "" {
+			received = true
+			onText(text)
+		}
+	}
+	return nil
+}
+
+// responseText joins the non-thought text parts of the first candidate.
+func responseText(resp *genai.GenerateContentResponse) string {
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	for _, part := range resp.Candidates[0].Content.Parts {
+		if !part.Thought {
+			sb.WriteString(part.Text)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,99 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/genai"
+)
+
+func TestWithRetry(t *testing.T) {
+	transient := genai.APIError{Code: http.StatusServiceUnavailable, Status: "UNAVAILABLE"}
+	permanent := genai.APIError{Code: http.StatusBadRequest, Status: "INVALID_ARGUMENT"}
+
+	tests := []struct {
+		name         string
+		maxRetries   int
+		failures     int
+		err          error
+		wantAttempts int
+		wantErr      string
+	}{
+		{name: "succeeds after transient errors", maxRetries: 3, failures: 2, err: transient, wantAttempts: 3},
+		{name: "gives up after max retries", maxRetries: 2, failures: 10, err: transient, wantAttempts: 3, wantErr: "gave up after 2 retries"},
+		{name: "does not retry permanent errors", maxRetries: 3, failures: 10, err: permanent, wantAttempts: 1, wantErr: "INVALID_ARGUMENT"},
+		{name: "retries disabled", maxRetries: 0, failures: 10, err: transient, wantAttempts: 1, wantErr: "UNAVAILABLE"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v := &VertexAIClient{maxRetries: tt.maxRetries, retryBaseDelay: time.Microsecond}
+			attempts := 0
+			err := v.withRetry(context.Background(), func() error {
+				attempts++
+				if attempts <= tt.failures {
+					return tt.err
+				}
+				return nil
+			})
+
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Errorf("withRetry() error = %v", err)
+				}
+			} else if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("withRetry() error = %v, want it to mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestWithRetryCancelled(t *testing.T) {
+	// Cancelling while waiting for the next attempt stops the retries
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	v := &VertexAIClient{maxRetries: 5, retryBaseDelay: time.Hour}
+
+	attempts := 0
+	err := v.withRetry(ctx, func() error {
+		attempts++
+		time.AfterFunc(10*time.Millisecond, cancel)
+		return genai.APIError{Code: http.StatusTooManyRequests}
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("withRetry() error = %v, want context.Canceled", err)
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1", attempts)
+	}
+}
+
+func TestRetryBackoff(t *testing.T) {
+	tests := []struct {
+		name    string
+		base    time.Duration
+		attempt int
+		want    time.Duration
+	}{
+		{name: "first attempt", base: time.Second, attempt: 0, want: time.Second},
+		{name: "doubles", base: time.Second, attempt: 3, want: 8 * time.Second},
+		{name: "capped", base: time.Second, attempt: 5, want: maxRetryDelay},
+		{name: "no overflow for large attempts", base: time.Second, attempt: 100, want: maxRetryDelay},
+		{name: "long base delay is kept", base: time.Minute, attempt: 4, want: time.Minute},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := retryBackoff(tt.base, tt.attempt); got != tt.want {
+				t.Errorf("retryBackoff(%s, %d) = %s, want %s", tt.base, tt.attempt, got, tt.want)
+			}
+		})
+	}
+}
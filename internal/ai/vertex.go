@@ -5,12 +5,44 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"slices"
 	"strings"
+	"text/template"
+	"time"
 
 	"github.com/EkeMinusYou/gelf/internal/config"
+	"github.com/EkeMinusYou/gelf/internal/git"
 	"google.golang.org/genai"
 )
 
+type CommitMessageInput struct {
+	Diff     string
+	Language string
+	Branch   string
+	// Concise asks for the shortest valid Conventional Commits message.
+	Concise bool
+	// Gitmoji prefixes the subject with the emoji matching its type.
+	Gitmoji bool
+	// MigrationNotes asks for a "BREAKING CHANGE:" footer describing the
+	// migration when the diff contains a breaking change.
+	MigrationNotes bool
+	// Scope forces the Conventional Commits scope of the subject.
+	Scope string
+	// Scopes is the allowlist of scopes the model may choose from. Scopes
+	// outside the list are stripped after generation.
+	Scopes []string
+	// MaxSubjectLength is the longest subject line allowed. Longer subjects
+	// are shortened by the model once and then truncated. Zero uses the
+	// default of 72.
+	MaxSubjectLength int
+	// Types replaces the default list of allowed commit types when set.
+	Types []string
+	// Rejected holds previously generated messages the user asked to
+	// regenerate. The model is asked for different phrasing and a higher
+	// temperature is used.
+	Rejected []string
+}
+
 type PullRequestInput struct {
 	BaseBranch    string
 	HeadBranch    string
@@ -29,12 +61,58 @@ type PullRequestContent struct {
 }
 
 type VertexAIClient struct {
-	client     *genai.Client
-	flashModel string
-	proModel   string
+	client               *genai.Client
+	flashModel           string
+	proModel             string
+	prelude              string
+	commitPromptTemplate *template.Template
+	maxRetries           int
+	retryBaseDelay       time.Duration
+	timeout              time.Duration
+	redactPaths          []string
+	commitTemperature    float32
+	prTemperature        float32
+	maxDiffTokens        int
 }
 
 func NewVertexAIClient(ctx context.Context, cfg *config.Config) (*VertexAIClient, error) {
+	var client *genai.Client
+	var err error
+	if cfg.Backend == config.BackendGemini {
+		client, err = newGeminiAPIClient(ctx, cfg)
+	} else {
+		client, err = newVertexClient(ctx, cfg)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var commitPromptTemplate *template.Template
+	if cfg.CommitPromptTemplate != "" {
+		commitPromptTemplate, err = config.ParseCommitPromptTemplate(cfg.CommitPromptTemplate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid commit.prompt_template: %w", err)
+		}
+	}
+
+	return &VertexAIClient{
+		client:               client,
+		flashModel:           cfg.FlashModel,
+		proModel:             cfg.ProModel,
+		commitPromptTemplate: commitPromptTemplate,
+		maxRetries:           cfg.AIMaxRetries,
+		retryBaseDelay:       cfg.AIRetryBaseDelay,
+		timeout:              cfg.AITimeout,
+		redactPaths:          cfg.AIRedactPaths,
+		commitTemperature:    cfg.CommitTemperature,
+		prTemperature:        cfg.PRTemperature,
+		maxDiffTokens:        cfg.CommitMaxDiffTokens,
+	}, nil
+}
+
+// newVertexClient creates a client for Vertex AI using GELF_CREDENTIALS,
+// GOOGLE_APPLICATION_CREDENTIALS or Application Default Credentials.
+func newVertexClient(ctx context.Context, cfg *config.Config) (*genai.Client, error) {
 	// Check for GELF_CREDENTIALS first, then fall back to GOOGLE_APPLICATION_CREDENTIALS
 	credentialsPath := os.Getenv("GELF_CREDENTIALS")
 	if credentialsPath == "" {
@@ -65,75 +143,418 @@ func NewVertexAIClient(ctx context.Context, cfg *config.Config) (*VertexAIClient
 	if err != nil {
 		return nil, fmt.Errorf("failed to create Vertex AI client: %w", err)
 	}
-
-	return &VertexAIClient{
-		client:     client,
-		flashModel: cfg.FlashModel,
-		proModel:   cfg.ProModel,
-	}, nil
+	return client, nil
 }
 
-func (v *VertexAIClient) GenerateCommitMessage(ctx context.Context, diff string, language string) (string, error) {
-	prompt := fmt.Sprintf(`Analyze the following git diff and generate a precise commit message following the Conventional Commits specification.
+// newGeminiAPIClient creates a client for the Gemini API using an API key
+// from GELF_API_KEY or GEMINI_API_KEY. Project and location are not used.
+func newGeminiAPIClient(ctx context.Context, cfg *config.Config) (*genai.Client, error) {
+	if cfg.APIKey == "" {
+		return nil, fmt.Errorf("backend %q requires an API key: set GELF_API_KEY or GEMINI_API_KEY", config.BackendGemini)
+	}
 
-DIFF ANALYSIS GUIDE:
-1. Look at file paths to understand what parts of the codebase are affected
-2. Examine +/- lines to understand what was added, removed, or modified
-3. Pay attention to function names, variable names, and code structure changes
-4. Consider the context lines (prefixed with space) to understand the surrounding code
-5. Identify the primary purpose: new feature, bug fix, refactoring, etc.
+	client, err := genai.NewClient(ctx, &genai.ClientConfig{
+		APIKey:  cfg.APIKey,
+		Backend: genai.BackendGeminiAPI,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Gemini API client: %w", err)
+	}
+	return client, nil
+}
 
-COMMIT MESSAGE REQUIREMENTS:
-1. Use %s language
-2. Follow format: <type>[optional scope]: <description>
-3. Valid types: feat, fix, docs, style, refactor, test, chore, perf, ci, build, revert
-4. Keep under 72 characters total
-5. Use imperative mood ("add" not "added")
-6. Start description with lowercase letter
-7. No period at the end
-8. If multiple changes, focus on the most significant one
-9. Use scope when it helps clarify the area of change (e.g., auth, api, ui)
+// SetPromptPrelude sets repository-specific context that is prepended to
+// every prompt sent by this client.
+func (v *VertexAIClient) SetPromptPrelude(prelude string) {
+	v.prelude = strings.TrimSpace(prelude)
+}
 
-EXAMPLES:
-- feat(auth): add JWT token validation
-- fix(api): resolve null pointer in user service
-- refactor(db): simplify connection pooling logic
-- test(payment): add unit tests for stripe integration
-- chore(deps): update react to version 18.2.0
+// UseRepoPromptPrelude loads the prompt prelude (.gelf/prompt.md) from the
+// root of the repository in the current directory and prepends it to every
+// prompt. Outside a repository there is no prelude.
+func (v *VertexAIClient) UseRepoPromptPrelude() error {
+	repoRoot, err := git.GetRepoRoot()
+	if err != nil {
+		return nil
+	}
 
-Git diff:
+	prelude, err := config.LoadPromptPrelude(repoRoot)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", config.PromptPreludePath, err)
+	}
+	v.SetPromptPrelude(prelude)
+	return nil
+}
+
+func (v *VertexAIClient) withPrelude(prompt string) string {
+	if v.prelude == "" {
+		return prompt
+	}
+	return fmt.Sprintf(`REPOSITORY CONTEXT (provided by the repository maintainers, use it to guide your output):
 %s
 
-Respond with only the commit message, no additional text or formatting.`, language, diff)
+%s`, v.prelude, prompt)
+}
+
+func (v *VertexAIClient) GenerateCommitMessage(ctx context.Context, input CommitMessageInput) (string, error) {
+	messages, err := v.GenerateCommitMessageCandidates(ctx, input, 1)
+	if err != nil {
+		return "", err
+	}
+	return messages[0], nil
+}
+
+// GenerateCommitMessageCandidates asks the model for up to count alternative
+// commit messages. Duplicates are removed, so fewer messages than requested
+// may be returned.
+func (v *VertexAIClient) GenerateCommitMessageCandidates(ctx context.Context, input CommitMessageInput, count int) ([]string, error) {
+	input, err := v.condenseLargeDiff(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	prompt, err := v.buildCommitPrompt(input)
+	if err != nil {
+		return nil, err
+	}
+
+	if count < 1 {
+		count = 1
+	}
 
-	resp, err := v.client.Models.GenerateContent(ctx, v.flashModel,
+	resp, err := v.generateContent(ctx, v.flashModel,
 		[]*genai.Content{
-			genai.NewContentFromText(prompt, genai.RoleUser),
+			genai.NewContentFromText(v.withPrelude(prompt), genai.RoleUser),
 		},
 		&genai.GenerateContentConfig{
-			Temperature: genai.Ptr(float32(0.3)),
+			Temperature:    genai.Ptr(v.temperatureForCommit(count, input)),
+			CandidateCount: int32(count),
 		})
 	if err != nil {
-		return "", fmt.Errorf("failed to generate commit message: %w", err)
+		return nil, fmt.Errorf("failed to generate commit message: %w", err)
 	}
 
 	if len(resp.Candidates) == 0 {
-		return "", fmt.Errorf("no candidates in response")
+		return nil, fmt.Errorf("no candidates in response")
 	}
 
-	if len(resp.Candidates[0].Content.Parts) == 0 {
-		return "", fmt.Errorf("no content parts in response")
+	var messages []string
+	seen := make(map[string]struct{})
+	for _, candidate := range resp.Candidates {
+		if candidate.Content == nil || len(candidate.Content.Parts) == 0 {
+			continue
+		}
+		text := strings.TrimSpace(candidate.Content.Parts[0].Text)
+		if text == "" {
+			continue
+		}
+		text = v.retryDisallowedScope(ctx, prompt, input, text)
+		// Compare post-processed messages, since scope stripping, gitmoji and
+		// subject shortening can make different raw candidates identical
+		message := v.enforceSubjectLength(ctx, postProcessCommitMessage(text, input), input)
+		if _, ok := seen[message]; ok {
+			continue
+		}
+		seen[message] = struct{}{}
+		messages = append(messages, message)
 	}
 
-	part := resp.Candidates[0].Content.Parts[0]
-	if part.Text == "" {
-		return "", fmt.Errorf("empty text in response part")
+	if len(messages) == 0 {
+		return nil, fmt.Errorf("empty text in response part")
+	}
+
+	return messages, nil
+}
+
+// GenerateCommitMessageStream generates a single commit message, calling
+// onProgress with the text received so far as the response streams in. The
+// returned message is the final, post-processed one.
+func (v *VertexAIClient) GenerateCommitMessageStream(ctx context.Context, input CommitMessageInput, onProgress func(string)) (string, error) {
+	input, err := v.condenseLargeDiff(ctx, input)
+	if err != nil {
+		return "", err
+	}
+
+	prompt, err := v.buildCommitPrompt(input)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	err = v.streamContent(ctx, v.flashModel,
+		[]*genai.Content{
+			genai.NewContentFromText(v.withPrelude(prompt), genai.RoleUser),
+		},
+		&genai.GenerateContentConfig{
+			Temperature: genai.Ptr(v.temperatureForCommit(1, input)),
+		},
+		func(text string) {
+			sb.WriteString(text)
+			onProgress(sb.String())
+		})
+	if err != nil {
+		return "", fmt.Errorf("failed to generate commit message: %w", err)
+	}
+
+	text := strings.TrimSpace(sb.String())
+	if text == "" {
+		return "", fmt.Errorf("empty text in response")
+	}
+	text = v.retryDisallowedScope(ctx, prompt, input, text)
+	return v.enforceSubjectLength(ctx, postProcessCommitMessage(text, input), input), nil
+}
+
+// retryDisallowedScope asks the model once more when text uses a scope
+// outside input.Scopes. The original text is returned when the retry fails;
+// a scope that is still not allowed is stripped by postProcessCommitMessage.
+func (v *VertexAIClient) retryDisallowedScope(ctx context.Context, prompt string, input CommitMessageInput, text string) string {
+	scope := git.MessageScope(text)
+	if input.Scope != "" || len(input.Scopes) == 0 || scope == "" || slices.Contains(input.Scopes, scope) {
+		return text
+	}
+
+	prompt += fmt.Sprintf(`
+
+A previous answer used the scope %q, which is not allowed. Use one of these scopes, or omit the scope if none fits: %s`, scope, strings.Join(input.Scopes, ", "))
+
+	resp, err := v.generateContent(ctx, v.flashModel,
+		[]*genai.Content{
+			genai.NewContentFromText(v.withPrelude(prompt), genai.RoleUser),
+		},
+		&genai.GenerateContentConfig{
+			Temperature: genai.Ptr(v.temperatureForCommit(1, input)),
+		})
+	if err != nil {
+		return text
+	}
+	if retried := strings.TrimSpace(responseText(resp)); retried != "" {
+		return retried
+	}
+	return text
+}
+
+// enforceSubjectLength asks the model once to shorten a subject over the
+// limit, and truncates it at a word boundary if it is still too long.
+func (v *VertexAIClient) enforceSubjectLength(ctx context.Context, message string, input CommitMessageInput) string {
+	maxLength := input.maxSubjectLength()
+	if git.SubjectLength(message) <= maxLength {
+		return message
+	}
+
+	prompt := fmt.Sprintf(`The subject line of the following commit message is %d characters long. Rewrite the subject so it is at most %d characters, keeping its type, scope, any leading emoji, and its meaning. Leave the body and footers unchanged.
+
+Respond with only the full commit message, no additional text or formatting.
+
+Commit message:
+%s`, git.SubjectLength(message), maxLength, message)
+
+	resp, err := v.generateContent(ctx, v.flashModel,
+		[]*genai.Content{
+			genai.NewContentFromText(prompt, genai.RoleUser),
+		},
+		&genai.GenerateContentConfig{
+			Temperature: genai.Ptr(v.commitTemperature),
+		})
+	if err == nil {
+		if shortened := strings.TrimSpace(responseText(resp)); shortened != "" {
+			message = shortened
+		}
+	}
+	return git.TruncateSubject(message, maxLength)
+}
+
+// temperatureForCommit starts from commit.temperature and raises it for
+// multiple candidates and regenerations so the results actually differ.
+func (v *VertexAIClient) temperatureForCommit(count int, input CommitMessageInput) float32 {
+	temperature := v.commitTemperature
+	if count > 1 {
+		temperature = max(temperature, 0.8)
+	}
+	return min(temperature+0.2*float32(len(input.Rejected)), max(v.commitTemperature, 1.0))
+}
+
+// postProcessCommitMessage applies the formatting options in input to a
+// generated message.
+func postProcessCommitMessage(text string, input CommitMessageInput) string {
+	if input.MigrationNotes {
+		text = git.NormalizeBreakingChange(text)
+	}
+	if input.Scope != "" {
+		text = git.SetScope(text, input.Scope)
+	} else {
+		text = git.RestrictScope(text, input.Scopes)
+	}
+	if input.Gitmoji {
+		text = git.ApplyGitmoji(text)
+	}
+	return text
+}
+
+// CountCommitTokens returns the number of input tokens the commit prompt for
+// input would use, without generating a message.
+func (v *VertexAIClient) CountCommitTokens(ctx context.Context, input CommitMessageInput) (int, error) {
+	prompt, err := v.buildCommitPrompt(input)
+	if err != nil {
+		return 0, err
+	}
+
+	if v.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, v.timeout)
+		defer cancel()
+	}
+
+	resp, err := v.client.Models.CountTokens(ctx, v.flashModel,
+		[]*genai.Content{
+			genai.NewContentFromText(v.withPrelude(prompt), genai.RoleUser),
+		}, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count tokens: %w", err)
+	}
+
+	return int(resp.TotalTokens), nil
+}
+
+// buildCommitPrompt renders the user-supplied commit.prompt_template when
+// configured, and the built-in Conventional Commits prompt otherwise.
+func (v *VertexAIClient) buildCommitPrompt(input CommitMessageInput) (string, error) {
+	input.Diff = git.RedactDiff(input.Diff, v.redactPaths)
+
+	prompt := defaultCommitPrompt(input)
+	if v.commitPromptTemplate != nil {
+		var sb strings.Builder
+		err := v.commitPromptTemplate.Execute(&sb, config.CommitPromptData{
+			Diff:     input.Diff,
+			Language: input.Language,
+			Branch:   input.Branch,
+		})
+		if err != nil {
+			return "", fmt.Errorf("failed to render commit.prompt_template: %w", err)
+		}
+		prompt = sb.String()
+	}
+
+	if len(input.Rejected) > 0 {
+		prompt += fmt.Sprintf(`
+
+The following commit messages were already suggested and rejected. Write a different message with noticeably different phrasing:
+- %s`, strings.Join(input.Rejected, "\n- "))
+	}
+
+	return prompt, nil
+}
+
+// defaultMaxSubjectLength is the subject length used when
+// CommitMessageInput.MaxSubjectLength is not set.
+const defaultMaxSubjectLength = 72
+
+func (input CommitMessageInput) maxSubjectLength() int {
+	if input.MaxSubjectLength > 0 {
+		return input.MaxSubjectLength
+	}
+	return defaultMaxSubjectLength
+}
+
+func defaultCommitPrompt(input CommitMessageInput) string {
+	types := input.Types
+	if len(types) == 0 {
+		types = git.ConventionalTypes
+	}
+
+	maxLength := input.maxSubjectLength()
+	if input.Gitmoji {
+		// Leave room for the gitmoji prefix added after generation
+		maxLength -= git.GitmojiWidth
+	}
+
+	requirements := []string{
+		fmt.Sprintf("Use %s language", input.Language),
+		"Follow format: <type>[optional scope]: <description>",
+		"Valid types: " + strings.Join(types, ", "),
+		fmt.Sprintf("Keep under %d characters total", maxLength),
+		`Use imperative mood ("add" not "added")`,
+		"Start description with lowercase letter",
+		"No period at the end",
+		"If multiple changes, focus on the most significant one",
+		"Use scope when it helps clarify the area of change (e.g., auth, api, ui)",
+	}
+	examples := []string{
+		"feat(auth): add JWT token validation",
+		"fix(api): resolve null pointer in user service",
+		"refactor(db): simplify connection pooling logic",
+		"test(payment): add unit tests for stripe integration",
+		"chore(deps): update react to version 18.2.0",
+	}
+
+	if input.Concise {
+		requirements[3] = fmt.Sprintf("Produce the shortest valid message: keep under %d characters total, ideally 2-4 words of description", min(maxLength, 50))
+		requirements[8] = "Omit the scope unless it is essential; if used, it must be a single word"
+		if !input.MigrationNotes {
+			requirements = append(requirements, "Never add a body or footer")
+		}
+		examples = []string{
+			"fix: typo",
+			"docs: fix broken link",
+			"chore(deps): bump cobra",
+			"style: format imports",
+		}
+	}
+
+	if input.Scope != "" {
+		requirements[8] = fmt.Sprintf("Always use the scope %q, e.g. <type>(%s): <description>", input.Scope, input.Scope)
+	} else if len(input.Scopes) > 0 {
+		requirements[8] = fmt.Sprintf("Only use a scope from this list, or omit the scope if none fits: %s", strings.Join(input.Scopes, ", "))
 	}
 
-	return part.Text, nil
+	if input.MigrationNotes {
+		requirements = append(requirements,
+			"If the change is breaking (removed or renamed public APIs, flags, config keys, changed defaults or behavior callers rely on), add \"!\" before the colon in the subject",
+			"For a breaking change, add a blank line after the subject followed by a footer \"BREAKING CHANGE: <one or two sentences telling users how to migrate>\"",
+			"If the change is not breaking, output only the subject line",
+		)
+		examples = append(examples, "feat(config)!: rename model.default to model.flash\n\nBREAKING CHANGE: rename model.default to model.flash in gelf.yml")
+	}
+
+	return fmt.Sprintf(`Analyze the following git diff and generate a precise commit message following the Conventional Commits specification.
+
+DIFF ANALYSIS GUIDE:
+1. Look at file paths to understand what parts of the codebase are affected
+2. Examine +/- lines to understand what was added, removed, or modified
+3. Pay attention to function names, variable names, and code structure changes
+4. Consider the context lines (prefixed with space) to understand the surrounding code
+5. Identify the primary purpose: new feature, bug fix, refactoring, etc.
+
+COMMIT MESSAGE REQUIREMENTS:
+%s
+
+EXAMPLES:
+%s
+
+Git diff:
+%s
+
+Respond with only the commit message, no additional text or formatting.`, numberedList(requirements), bulletList(examples), input.Diff)
+}
+
+func numberedList(items []string) string {
+	lines := make([]string, len(items))
+	for i, item := range items {
+		lines[i] = fmt.Sprintf("%d. %s", i+1, item)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func bulletList(items []string) string {
+	lines := make([]string, len(items))
+	for i, item := range items {
+		lines[i] = "- " + item
+	}
+	return strings.Join(lines, "\n")
 }
 
 func (v *VertexAIClient) GeneratePullRequestContent(ctx context.Context, input PullRequestInput) (*PullRequestContent, error) {
+	input.Diff = git.RedactDiff(input.Diff, v.redactPaths)
+
 	template := input.Template
 	if strings.TrimSpace(template) == "" {
 		template = "NONE"
@@ -189,12 +610,12 @@ PR_TEMPLATE:
 %s
 `, titleLanguage, bodyLanguage, input.BaseBranch, input.HeadBranch, input.CommitLog, input.DiffStat, input.Diff, template)
 
-	resp, err := v.client.Models.GenerateContent(ctx, v.flashModel,
+	resp, err := v.generateContent(ctx, v.flashModel,
 		[]*genai.Content{
-			genai.NewContentFromText(prompt, genai.RoleUser),
+			genai.NewContentFromText(v.withPrelude(prompt), genai.RoleUser),
 		},
 		&genai.GenerateContentConfig{
-			Temperature: genai.Ptr(float32(0.2)),
+			Temperature: genai.Ptr(v.prTemperature),
 		})
 	if err != nil {
 		return nil, fmt.Errorf("failed to generate pull request content: %w", err)
@@ -237,6 +658,19 @@ PR_TEMPLATE:
 	return &result, nil
 }
 
+// Ping sends a minimal request to the commit model to check that the
+// credentials, project and model name work.
+func (v *VertexAIClient) Ping(ctx context.Context) error {
+	_, err := v.generateContentOnce(ctx, v.flashModel,
+		[]*genai.Content{
+			genai.NewContentFromText("Reply with OK.", genai.RoleUser),
+		},
+		&genai.GenerateContentConfig{
+			Temperature: genai.Ptr(float32(0)),
+		})
+	return err
+}
+
 func (v *VertexAIClient) Close() error {
 	return nil
 }
@@ -0,0 +1,166 @@
+package ai
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/EkeMinusYou/gelf/internal/git"
+)
+
+func TestWithPrelude(t *testing.T) {
+	v := &VertexAIClient{}
+	if got := v.withPrelude("PROMPT"); got != "PROMPT" {
+		t.Errorf("without prelude: got %q, want the prompt unchanged", got)
+	}
+
+	v.SetPromptPrelude("  Follow the team glossary.\n")
+	got := v.withPrelude("PROMPT")
+	if !strings.HasPrefix(got, "REPOSITORY CONTEXT") {
+		t.Errorf("prompt does not start with the repository context: %q", got)
+	}
+	if !strings.Contains(got, "Follow the team glossary.\n\nPROMPT") {
+		t.Errorf("prelude is not placed before the prompt: %q", got)
+	}
+}
+
+// promptExamples returns the example messages listed in a commit prompt.
+func promptExamples(t *testing.T, prompt string) []string {
+	t.Helper()
+	_, rest, ok := strings.Cut(prompt, "EXAMPLES:\n")
+	if !ok {
+		t.Fatal("prompt has no EXAMPLES section")
+	}
+	section, _, _ := strings.Cut(rest, "\n\nGit diff:")
+	var examples []string
+	for _, example := range strings.Split(section, "\n- ") {
+		examples = append(examples, strings.TrimPrefix(example, "- "))
+	}
+	return examples
+}
+
+func TestDefaultCommitPromptConcise(t *testing.T) {
+	tests := []struct {
+		name           string
+		input          CommitMessageInput
+		wantContains   []string
+		wantNotContain []string
+	}{
+		{
+			name:  "concise",
+			input: CommitMessageInput{Language: "english", Concise: true},
+			wantContains: []string{
+				"Produce the shortest valid message: keep under 50 characters total",
+				"Omit the scope unless it is essential",
+				"Never add a body or footer",
+				"- fix: typo",
+			},
+			wantNotContain: []string{"BREAKING CHANGE", "feat(auth): add JWT token validation"},
+		},
+		{
+			name:  "concise with a short subject limit",
+			input: CommitMessageInput{Language: "english", Concise: true, MaxSubjectLength: 40},
+			wantContains: []string{
+				"keep under 40 characters total",
+			},
+		},
+		{
+			name:  "concise with migration notes",
+			input: CommitMessageInput{Language: "english", Concise: true, MigrationNotes: true},
+			wantContains: []string{
+				"Produce the shortest valid message",
+				`add "!" before the colon in the subject`,
+				"BREAKING CHANGE: <one or two sentences",
+			},
+			wantNotContain: []string{"Never add a body or footer"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prompt := defaultCommitPrompt(tt.input)
+			for _, want := range tt.wantContains {
+				if !strings.Contains(prompt, want) {
+					t.Errorf("prompt does not contain %q", want)
+				}
+			}
+			for _, unwanted := range tt.wantNotContain {
+				if strings.Contains(prompt, unwanted) {
+					t.Errorf("prompt contains %q", unwanted)
+				}
+			}
+			for _, example := range promptExamples(t, prompt) {
+				if err := git.ValidateConventionalCommit(example, nil); err != nil {
+					t.Errorf("example %q is not a valid Conventional Commit: %v", example, err)
+				}
+			}
+		})
+	}
+}
+
+func TestDefaultCommitPromptScopes(t *testing.T) {
+	tests := []struct {
+		name  string
+		input CommitMessageInput
+		want  string
+	}{
+		{
+			name:  "no scopes configured",
+			input: CommitMessageInput{Language: "english"},
+			want:  "Use scope when it helps clarify the area of change",
+		},
+		{
+			name:  "allowed scopes",
+			input: CommitMessageInput{Language: "english", Scopes: []string{"api", "ui"}},
+			want:  "Only use a scope from this list, or omit the scope if none fits: api, ui",
+		},
+		{
+			name:  "forced scope",
+			input: CommitMessageInput{Language: "english", Scope: "cli", Scopes: []string{"api", "cli"}},
+			want:  `Always use the scope "cli", e.g. <type>(cli): <description>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if prompt := defaultCommitPrompt(tt.input); !strings.Contains(prompt, tt.want) {
+				t.Errorf("prompt does not contain %q", tt.want)
+			}
+		})
+	}
+}
+
+func TestPostProcessCommitMessageScope(t *testing.T) {
+	tests := []struct {
+		name  string
+		text  string
+		input CommitMessageInput
+		want  string
+	}{
+		{
+			name:  "disallowed scope is removed",
+			text:  "feat(db): add index",
+			input: CommitMessageInput{Scopes: []string{"api", "ui"}},
+			want:  "feat: add index",
+		},
+		{
+			name:  "allowed scope is kept",
+			text:  "feat(api): add pagination",
+			input: CommitMessageInput{Scopes: []string{"api", "ui"}},
+			want:  "feat(api): add pagination",
+		},
+		{
+			name:  "forced scope replaces the generated one",
+			text:  "fix(api): handle timeouts",
+			input: CommitMessageInput{Scope: "client", Scopes: []string{"api"}},
+			want:  "fix(client): handle timeouts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := postProcessCommitMessage(tt.text, tt.input); got != tt.want {
+				t.Errorf("postProcessCommitMessage(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,29 +1,86 @@
 package config
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
 	"os"
+	"path"
 	"path/filepath"
+	"regexp"
+	"time"
 
+	"golang.org/x/term"
 	"gopkg.in/yaml.v3"
 )
 
+// Default model names used when model.flash / model.pro are not configured.
+const (
+	DefaultFlashModel = "gemini-3-flash-preview"
+	DefaultProModel   = "gemini-3.1-pro-preview"
+)
+
+// MaxAIRetries is the largest accepted ai.max_retries.
+const MaxAIRetries = 10
+
+// Supported values for the backend setting.
+const (
+	BackendVertexAI = "vertex_ai"
+	BackendGemini   = "gemini"
+)
+
 type Config struct {
-	ProjectID       string
-	Location        string
-	FlashModel      string
-	ProModel        string
-	BaseFlashModel  string
-	BaseProModel    string
-	CommitLanguage  string
-	CommitModel     string
-	PRLanguage      string
-	PRTitleLanguage string
-	PRBodyLanguage  string
-	PRModel         string
-	Color           string
+	Backend                string
+	APIKey                 string
+	ProjectID              string
+	Location               string
+	FlashModel             string
+	ProModel               string
+	BaseFlashModel         string
+	BaseProModel           string
+	CommitLanguage         string
+	CommitModel            string
+	CommitTicketPattern    string
+	CommitTicketPlacement  string
+	CommitPromptTemplate   string
+	CommitSignoff          bool
+	CommitGitmoji          bool
+	CommitScopes           []string
+	CommitTypes            []string
+	CommitAutoScopes       bool
+	CommitFallbackFormat   string
+	CommitTemperature      float32
+	CommitMaxDiffTokens    int
+	CommitMaxSubjectLength int
+	PRLanguage             string
+	PRTitleLanguage        string
+	PRBodyLanguage         string
+	PRModel                string
+	PRTemperature          float32
+	Color                  string
+	UIMaxSummaryFiles      int
+	DiffContextLines       int
+	DiffExclude            []string
+	HooksOnCommit          string
+	AIMaxRetries           int
+	AIRetryBaseDelay       time.Duration
+	AITimeout              time.Duration
+	AIRedactPaths          []string
+	Pricing                map[string]ModelPricing
+	// Resolutions records how each key (such as "commit.language") was
+	// resolved: every source considered and which one won.
+	Resolutions map[string]*Resolution
+}
+
+// ModelPricing is the price in USD per million tokens for a model, used to
+// estimate the cost of a request.
+type ModelPricing struct {
+	InputPerMillion float64 `yaml:"input_per_million" json:"input_per_million"`
 }
 
 type FileConfig struct {
+	Backend  string `yaml:"backend"`
 	VertexAI struct {
 		ProjectID string `yaml:"project_id"`
 		Location  string `yaml:"location"`
@@ -35,97 +92,195 @@ type FileConfig struct {
 	Language string `yaml:"language"`
 	Color    string `yaml:"color"`
 	Commit   struct {
-		Model    string `yaml:"model"`
-		Language string `yaml:"language"`
+		Model            string   `yaml:"model"`
+		Language         string   `yaml:"language"`
+		TicketPattern    string   `yaml:"ticket_pattern"`
+		TicketPlacement  string   `yaml:"ticket_placement"`
+		PromptTemplate   string   `yaml:"prompt_template"`
+		Signoff          bool     `yaml:"signoff"`
+		Gitmoji          bool     `yaml:"gitmoji"`
+		Scopes           []string `yaml:"scopes"`
+		Types            []string `yaml:"types"`
+		AutoScopes       bool     `yaml:"auto_scopes"`
+		FallbackFormat   string   `yaml:"fallback_format"`
+		Temperature      *float32 `yaml:"temperature"`
+		MaxDiffTokens    *int     `yaml:"max_diff_tokens"`
+		MaxSubjectLength *int     `yaml:"max_subject_length"`
 	} `yaml:"commit"`
 	PR struct {
-		Model         string `yaml:"model"`
-		Language      string `yaml:"language"`
-		TitleLanguage string `yaml:"title_language"`
-		BodyLanguage  string `yaml:"body_language"`
+		Model         string   `yaml:"model"`
+		Language      string   `yaml:"language"`
+		TitleLanguage string   `yaml:"title_language"`
+		BodyLanguage  string   `yaml:"body_language"`
+		Temperature   *float32 `yaml:"temperature"`
 	} `yaml:"pr"`
+	UI struct {
+		MaxSummaryFiles *int `yaml:"max_summary_files"`
+	} `yaml:"ui"`
+	Diff struct {
+		ContextLines *int     `yaml:"context_lines"`
+		Exclude      []string `yaml:"exclude"`
+	} `yaml:"diff"`
+	Hooks struct {
+		OnCommit string `yaml:"on_commit"`
+	} `yaml:"hooks"`
+	AI struct {
+		MaxRetries     *int     `yaml:"max_retries"`
+		RetryBaseDelay string   `yaml:"retry_base_delay"`
+		Timeout        string   `yaml:"timeout"`
+		RedactPaths    []string `yaml:"redact_paths"`
+	} `yaml:"ai"`
+	Pricing map[string]ModelPricing `yaml:"pricing"`
 }
 
 func Load() (*Config, error) {
 	// Load from file first (lowest priority)
-	fileConfig, err := loadFromFile()
-	if err != nil {
-		// File not found or invalid is not an error - use defaults
+	fileConfig, configPath, err := loadFromFile()
+	if errors.Is(err, os.ErrNotExist) {
+		// No config file is not an error - use defaults
 		fileConfig = &FileConfig{}
+		configPath = ""
+	} else if err != nil {
+		return nil, fmt.Errorf("%s: %w", configPath, err)
 	}
 
-	// Environment variables override file config
-	projectID := os.Getenv("VERTEXAI_PROJECT")
-	if projectID == "" {
-		projectID = os.Getenv("GOOGLE_CLOUD_PROJECT")
+	// Every key is resolved through keySpecs (environment variables, then
+	// the file, then inherited keys and defaults), recording where each
+	// value came from
+	r := newResolver(fileConfig, configPath)
+
+	// Backend: Vertex AI (default) or the Gemini API with an API key
+	backend := r.string("backend")
+	if backend != BackendVertexAI && backend != BackendGemini {
+		return nil, fmt.Errorf("invalid backend %q: must be %q or %q", backend, BackendVertexAI, BackendGemini)
 	}
-	if projectID == "" {
-		projectID = fileConfig.VertexAI.ProjectID
+
+	apiKey := os.Getenv("GELF_API_KEY")
+	if apiKey == "" {
+		apiKey = os.Getenv("GEMINI_API_KEY")
 	}
 
-	location := os.Getenv("VERTEXAI_LOCATION")
-	if location == "" {
-		location = fileConfig.VertexAI.Location
+	projectID := r.string("vertex_ai.project_id")
+	location := r.string("vertex_ai.location")
+
+	// Define model names
+	flashModel := r.string("model.flash")
+	proModel := r.string("model.pro")
+
+	// Commit settings
+	commitModel := r.string("commit.model")
+	commitLanguage := r.string("commit.language")
+
+	// Ticket detection from branch names (disabled unless a pattern is configured)
+	commitTicketPattern := r.string("commit.ticket_pattern")
+	if commitTicketPattern != "" {
+		if _, err := regexp.Compile(commitTicketPattern); err != nil {
+			return nil, fmt.Errorf("invalid commit.ticket_pattern: %w", err)
+		}
 	}
-	if location == "" {
-		location = "global"
+	commitTicketPlacement := r.string("commit.ticket_placement")
+	if commitTicketPlacement != "footer" && commitTicketPlacement != "scope" {
+		return nil, fmt.Errorf("invalid commit.ticket_placement %q: must be \"footer\" or \"scope\"", commitTicketPlacement)
 	}
 
-	// Define model names
-	flashModel := fileConfig.Model.Flash
-	if flashModel == "" {
-		flashModel = "gemini-3-flash-preview"
+	// Custom commit prompt (falls back to the built-in prompt when empty)
+	commitPromptTemplate := r.string("commit.prompt_template")
+	if commitPromptTemplate != "" {
+		if _, err := ParseCommitPromptTemplate(commitPromptTemplate); err != nil {
+			return nil, fmt.Errorf("invalid commit.prompt_template: %w", err)
+		}
 	}
 
-	proModel := fileConfig.Model.Pro
-	if proModel == "" {
-		proModel = "gemini-3.1-pro-preview"
+	// Message used by --allow-fallback when generation fails
+	commitFallbackFormat := r.string("commit.fallback_format")
+	if _, err := ParseCommitFallbackTemplate(commitFallbackFormat); err != nil {
+		return nil, fmt.Errorf("invalid commit.fallback_format: %w", err)
 	}
 
-	// Default language
-	defaultLanguage := fileConfig.Language
-	if defaultLanguage == "" {
-		defaultLanguage = "english"
+	// Sampling temperatures (defaults match the previous built-in values)
+	commitTemperature := r.float("commit.temperature")
+	if err := checkTemperature("commit.temperature", commitTemperature); err != nil {
+		return nil, err
 	}
 
-	// Commit settings
-	commitModel := fileConfig.Commit.Model
-	if commitModel == "" {
-		commitModel = "flash" // default to flash model
+	// Diffs estimated above this many tokens are summarized in chunks
+	// before the commit message is generated (0 disables chunking)
+	commitMaxDiffTokens := r.int("commit.max_diff_tokens")
+	if commitMaxDiffTokens < 0 {
+		return nil, fmt.Errorf("invalid commit.max_diff_tokens: must not be negative")
 	}
 
-	commitLanguage := fileConfig.Commit.Language
-	if commitLanguage == "" {
-		commitLanguage = defaultLanguage
+	// An explicit but empty type list would reject every message
+	commitTypes := r.list("commit.types")
+	if commitTypes != nil && len(commitTypes) == 0 {
+		return nil, fmt.Errorf("invalid commit.types: must not be empty")
 	}
 
-	// PR settings
-	prModel := fileConfig.PR.Model
-	if prModel == "" {
-		prModel = "pro" // default to pro model
+	// Longest subject line allowed in generated commit messages
+	commitMaxSubjectLength := r.int("commit.max_subject_length")
+	if commitMaxSubjectLength <= 0 {
+		return nil, fmt.Errorf("invalid commit.max_subject_length: must be positive")
 	}
 
-	prLanguage := fileConfig.PR.Language
-	if prLanguage == "" {
-		prLanguage = defaultLanguage
+	// PR settings (languages default to pr.language, then the global language)
+	prModel := r.string("pr.model")
+	prLanguage := r.string("pr.language")
+	prTitleLanguage := r.string("pr.title_language")
+	prBodyLanguage := r.string("pr.body_language")
+
+	prTemperature := r.float("pr.temperature")
+	if err := checkTemperature("pr.temperature", prTemperature); err != nil {
+		return nil, err
 	}
 
-	// PR title language (defaults to pr.language, then global language)
-	prTitleLanguage := fileConfig.PR.TitleLanguage
-	if prTitleLanguage == "" {
-		prTitleLanguage = prLanguage
+	// Color settings
+	color := r.string("color")
+	if color != "auto" && color != "always" && color != "never" {
+		return nil, fmt.Errorf("invalid color %q: must be \"auto\", \"always\" or \"never\"", color)
 	}
 
-	// PR body language (defaults to pr.language, then global language)
-	prBodyLanguage := fileConfig.PR.BodyLanguage
-	if prBodyLanguage == "" {
-		prBodyLanguage = prLanguage
+	// Number of files listed in diff summaries (0 lists every file)
+	uiMaxSummaryFiles := r.int("ui.max_summary_files")
+	if uiMaxSummaryFiles < 0 {
+		return nil, fmt.Errorf("invalid ui.max_summary_files: must not be negative")
 	}
 
-	// Color settings
-	color := fileConfig.Color
-	if color == "" {
-		color = "always" // default to always
+	// Unchanged lines shown around each change in diffs
+	diffContextLines := r.int("diff.context_lines")
+	if diffContextLines < 0 {
+		return nil, fmt.Errorf("invalid diff.context_lines: must not be negative")
+	}
+
+	// Retry settings for transient AI errors
+	aiMaxRetries := r.int("ai.max_retries")
+	if aiMaxRetries < 0 || aiMaxRetries > MaxAIRetries {
+		return nil, fmt.Errorf("invalid ai.max_retries: must be between 0 and %d", MaxAIRetries)
+	}
+
+	aiRetryBaseDelay, err := parsePositiveDuration("ai.retry_base_delay", r.string("ai.retry_base_delay"))
+	if err != nil {
+		return nil, err
+	}
+
+	aiTimeout, err := parsePositiveDuration("ai.timeout", r.string("ai.timeout"))
+	if err != nil {
+		return nil, err
+	}
+
+	// Files whose diff content is never sent to the model
+	aiRedactPaths := r.list("ai.redact_paths")
+	for _, pattern := range aiRedactPaths {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid ai.redact_paths pattern %q: %w", pattern, err)
+		}
+	}
+
+	// Price table for token cost estimates, keyed by model name
+	pricing, _ := r.resolve("pricing").Value.(map[string]ModelPricing)
+	for name, modelPricing := range pricing {
+		if modelPricing.InputPerMillion < 0 {
+			return nil, fmt.Errorf("invalid pricing.%s.input_per_million: must not be negative", name)
+		}
 	}
 
 	// Resolve actual model names
@@ -140,40 +295,122 @@ func Load() (*Config, error) {
 	}
 
 	return &Config{
-		ProjectID:       projectID,
-		Location:        location,
-		FlashModel:      actualFlashModel,
-		ProModel:        proModel,
-		BaseFlashModel:  flashModel,
-		BaseProModel:    proModel,
-		CommitLanguage:  commitLanguage,
-		CommitModel:     commitModel,
-		PRLanguage:      prLanguage,
-		PRTitleLanguage: prTitleLanguage,
-		PRBodyLanguage:  prBodyLanguage,
-		PRModel:         prModel,
-		Color:           color,
+		Backend:                backend,
+		APIKey:                 apiKey,
+		ProjectID:              projectID,
+		Location:               location,
+		FlashModel:             actualFlashModel,
+		ProModel:               proModel,
+		BaseFlashModel:         flashModel,
+		BaseProModel:           proModel,
+		CommitLanguage:         commitLanguage,
+		CommitModel:            commitModel,
+		CommitTicketPattern:    commitTicketPattern,
+		CommitTicketPlacement:  commitTicketPlacement,
+		CommitPromptTemplate:   commitPromptTemplate,
+		CommitSignoff:          r.bool("commit.signoff"),
+		CommitGitmoji:          r.bool("commit.gitmoji"),
+		CommitScopes:           r.list("commit.scopes"),
+		CommitTypes:            commitTypes,
+		CommitAutoScopes:       r.bool("commit.auto_scopes"),
+		CommitFallbackFormat:   commitFallbackFormat,
+		CommitTemperature:      commitTemperature,
+		CommitMaxDiffTokens:    commitMaxDiffTokens,
+		CommitMaxSubjectLength: commitMaxSubjectLength,
+		PRLanguage:             prLanguage,
+		PRTitleLanguage:        prTitleLanguage,
+		PRBodyLanguage:         prBodyLanguage,
+		PRModel:                prModel,
+		PRTemperature:          prTemperature,
+		Color:                  color,
+		UIMaxSummaryFiles:      uiMaxSummaryFiles,
+		DiffContextLines:       diffContextLines,
+		DiffExclude:            r.list("diff.exclude"),
+		HooksOnCommit:          r.string("hooks.on_commit"),
+		AIMaxRetries:           aiMaxRetries,
+		AIRetryBaseDelay:       aiRetryBaseDelay,
+		AITimeout:              aiTimeout,
+		AIRedactPaths:          aiRedactPaths,
+		Pricing:                pricing,
+		Resolutions:            r.all(),
 	}, nil
 }
 
-func loadFromFile() (*FileConfig, error) {
-	// Try to find gelf.yml in current directory, XDG config, or home directory
+// loadFromFile reads the first config file found and returns it along with
+// its path.
+func loadFromFile() (*FileConfig, string, error) {
+	configPaths := searchPaths()
+
+	for _, path := range configPaths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue // Try next path
+		}
+
+		config, err := decodeFileConfig(data)
+		if err != nil {
+			return nil, path, err
+		}
+		return config, path, nil
+	}
+
+	return nil, "", os.ErrNotExist
+}
+
+// decodeFileConfig decodes data strictly, so misspelled keys are reported
+// instead of silently ignored.
+func decodeFileConfig(data []byte) (*FileConfig, error) {
+	var config FileConfig
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+		var typeErr *yaml.TypeError
+		if errors.As(err, &typeErr) {
+			for i, msg := range typeErr.Errors {
+				typeErr.Errors[i] = unknownFieldPattern.ReplaceAllString(msg, `unknown key "$1"`)
+			}
+		}
+		return nil, err
+	}
+	return &config, nil
+}
+
+var unknownFieldPattern = regexp.MustCompile(`field (\S+) not found in type \S+`)
+
+// checkTemperature reports an error for temperatures outside [0, 2].
+func checkTemperature(key string, value float32) error {
+	if value < 0 || value > 2 {
+		return fmt.Errorf("invalid %s: %g is outside the range [0, 2]", key, value)
+	}
+	return nil
+}
+
+// parsePositiveDuration parses the duration value of key, which must be
+// positive.
+func parsePositiveDuration(key, value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s: %w", key, err)
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("invalid %s: must be positive", key)
+	}
+	return d, nil
+}
+
+// searchPaths returns the config file locations in priority order: the
+// current directory, the XDG config directory, then the home directory.
+func searchPaths() []string {
 	configPaths := []string{
 		"gelf.yml",
 		"gelf.yaml",
 	}
 
 	// Add XDG config directory paths
-	if xdgConfigHome := os.Getenv("XDG_CONFIG_HOME"); xdgConfigHome != "" {
-		configPaths = append(configPaths,
-			filepath.Join(xdgConfigHome, "gelf", "gelf.yml"),
-			filepath.Join(xdgConfigHome, "gelf", "gelf.yaml"),
-		)
-	} else if homeDir, err := os.UserHomeDir(); err == nil {
-		// Fallback to ~/.config if XDG_CONFIG_HOME is not set
+	if dir, err := userConfigDir(); err == nil {
 		configPaths = append(configPaths,
-			filepath.Join(homeDir, ".config", "gelf", "gelf.yml"),
-			filepath.Join(homeDir, ".config", "gelf", "gelf.yaml"),
+			filepath.Join(dir, "gelf.yml"),
+			filepath.Join(dir, "gelf.yaml"),
 		)
 	}
 
@@ -185,22 +422,46 @@ func loadFromFile() (*FileConfig, error) {
 		)
 	}
 
-	var config FileConfig
-	for _, path := range configPaths {
-		data, err := os.ReadFile(path)
-		if err != nil {
-			continue // Try next path
-		}
+	return configPaths
+}
 
-		if err := yaml.Unmarshal(data, &config); err != nil {
-			return nil, err
+// FindConfigFile returns the path of the config file Load reads, or false
+// when no config file exists.
+func FindConfigFile() (string, bool) {
+	for _, path := range searchPaths() {
+		if info, err := os.Stat(path); err == nil && !info.IsDir() {
+			return path, true
 		}
-		return &config, nil
 	}
+	return "", false
+}
 
-	return nil, os.ErrNotExist
+// userConfigDir returns $XDG_CONFIG_HOME/gelf, falling back to
+// ~/.config/gelf when XDG_CONFIG_HOME is not set.
+func userConfigDir() (string, error) {
+	if xdgConfigHome := os.Getenv("XDG_CONFIG_HOME"); xdgConfigHome != "" {
+		return filepath.Join(xdgConfigHome, "gelf"), nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".config", "gelf"), nil
+}
+
+// UserConfigPath returns the path of the user-level gelf.yml in the XDG
+// config directory.
+func UserConfigPath() (string, error) {
+	dir, err := userConfigDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "gelf.yml"), nil
 }
 
+// UseColor reports whether output should be styled. "always" and "never"
+// force the choice; "auto" colors only when stdout is a terminal and the
+// NO_COLOR environment variable is not set.
 func (c *Config) UseColor() bool {
 	switch c.Color {
 	case "never":
@@ -208,7 +469,10 @@ func (c *Config) UseColor() bool {
 	case "always":
 		return true
 	default:
-		return true
+		if os.Getenv("NO_COLOR") != "" {
+			return false
+		}
+		return term.IsTerminal(int(os.Stdout.Fd()))
 	}
 }
 
@@ -228,3 +492,13 @@ func (c *Config) ResolveModel(name string) string {
 		return name
 	}
 }
+
+// EstimateInputCost returns the estimated USD cost of sending tokens input
+// tokens to model, and false when no price is configured for the model.
+func (c *Config) EstimateInputCost(model string, tokens int) (float64, bool) {
+	pricing, ok := c.Pricing[model]
+	if !ok {
+		return 0, false
+	}
+	return float64(tokens) * pricing.InputPerMillion / 1_000_000, true
+}
@@ -0,0 +1,40 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestLoadMaxRetries(t *testing.T) {
+	tests := []struct {
+		retries int
+		wantErr bool
+	}{
+		{retries: 0},
+		{retries: MaxAIRetries},
+		{retries: MaxAIRetries + 1, wantErr: true},
+		{retries: 64, wantErr: true},
+		{retries: -1, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.retries), func(t *testing.T) {
+			useConfigFile(t, fmt.Sprintf("vertex_ai:\n  project_id: p\nai:\n  max_retries: %d\n", tt.retries))
+
+			cfg, err := Load()
+			if tt.wantErr {
+				if err == nil || !strings.Contains(err.Error(), "ai.max_retries") {
+					t.Fatalf("Load() error = %v, want an ai.max_retries error", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if cfg.AIMaxRetries != tt.retries {
+				t.Errorf("AIMaxRetries = %d, want %d", cfg.AIMaxRetries, tt.retries)
+			}
+		})
+	}
+}
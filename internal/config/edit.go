@@ -0,0 +1,203 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// GetFileValue returns the value of the dotted key (e.g. "commit.language")
+// in the config file at path, and false when the file or key does not exist.
+// Non-scalar values are returned as YAML.
+func GetFileValue(path, key string) (string, bool, error) {
+	if err := validateKey(key); err != nil {
+		return "", false, err
+	}
+
+	doc, err := readDocument(path)
+	if err != nil {
+		return "", false, err
+	}
+
+	node := doc.Content[0]
+	for _, part := range strings.Split(key, ".") {
+		node = mappingValue(node, part)
+		if node == nil {
+			return "", false, nil
+		}
+	}
+
+	if node.Kind == yaml.ScalarNode {
+		return node.Value, true, nil
+	}
+	out, err := yaml.Marshal(node)
+	if err != nil {
+		return "", false, err
+	}
+	return strings.TrimSpace(string(out)), true, nil
+}
+
+// SetFileValue sets the dotted key to value in the config file at path,
+// creating the file and any intermediate sections as needed. Comments and
+// the order of existing keys are preserved. Values of string keys are
+// written as given; other values are parsed as YAML, so "[api, ui]" sets a
+// list and "true" a boolean.
+func SetFileValue(path, key, value string) error {
+	t, err := keyType(key)
+	if err != nil {
+		return err
+	}
+
+	doc, err := readDocument(path)
+	if err != nil {
+		return err
+	}
+
+	// Strings such as "[A-Z]+-[0-9]+" would otherwise parse as YAML lists
+	valueNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value}
+	if t.Kind() != reflect.String {
+		var parsed yaml.Node
+		if err := yaml.Unmarshal([]byte(value), &parsed); err != nil {
+			return fmt.Errorf("invalid value %q: %w", value, err)
+		}
+		valueNode = &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: ""}
+		if len(parsed.Content) > 0 {
+			valueNode = parsed.Content[0]
+		}
+	}
+
+	node := doc.Content[0]
+	parts := strings.Split(key, ".")
+	for _, part := range parts[:len(parts)-1] {
+		child := mappingValue(node, part)
+		if child == nil || child.Kind != yaml.MappingNode {
+			child = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+			setMappingValue(node, part, child)
+		}
+		node = child
+	}
+	setMappingValue(node, parts[len(parts)-1], valueNode)
+
+	var buf bytes.Buffer
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(doc); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", path, err)
+	}
+
+	// Make sure the result still decodes into the config schema
+	if _, err := decodeFileConfig(buf.Bytes()); err != nil {
+		return fmt.Errorf("invalid value for %s: %w", key, err)
+	}
+
+	return os.WriteFile(path, buf.Bytes(), 0o644)
+}
+
+// readDocument parses the YAML file at path, returning an empty document
+// with a root mapping when the file does not exist or is empty.
+func readDocument(path string) (*yaml.Node, error) {
+	doc := &yaml.Node{Kind: yaml.DocumentNode}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(data)) > 0 {
+		if err := yaml.Unmarshal(data, doc); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+		}
+	}
+
+	if len(doc.Content) == 0 {
+		doc.Kind = yaml.DocumentNode
+		doc.Content = []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}
+	}
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("%s: top level must be a mapping", path)
+	}
+	return doc, nil
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func setMappingValue(node *yaml.Node, key string, value *yaml.Node) {
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			// Keep comments attached to the old value
+			value.HeadComment = node.Content[i+1].HeadComment
+			value.LineComment = node.Content[i+1].LineComment
+			node.Content[i+1] = value
+			return
+		}
+	}
+	node.Content = append(node.Content,
+		&yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key},
+		value,
+	)
+}
+
+// validateKey checks that the dotted key names a field of FileConfig. Keys
+// below a map (such as pricing.<model>) accept any name.
+func validateKey(key string) error {
+	_, err := keyType(key)
+	return err
+}
+
+// keyType returns the type of the FileConfig field named by the dotted key.
+func keyType(key string) (reflect.Type, error) {
+	t := reflect.TypeOf(FileConfig{})
+	for _, part := range strings.Split(key, ".") {
+		for t.Kind() == reflect.Pointer {
+			t = t.Elem()
+		}
+
+		switch t.Kind() {
+		case reflect.Map:
+			t = t.Elem()
+			continue
+		case reflect.Struct:
+		default:
+			return nil, fmt.Errorf("unknown configuration key %q", key)
+		}
+
+		found := false
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			if name == part {
+				t = field.Type
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, fmt.Errorf("unknown configuration key %q", key)
+		}
+	}
+
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Kind() == reflect.Struct {
+		return nil, fmt.Errorf("%q is a section; specify a key inside it", key)
+	}
+	return t, nil
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestSetFileValue(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gelf.yml")
+	if err := os.WriteFile(path, []byte("# gelf settings\ncommit:\n  language: english # default\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	values := map[string]string{
+		"commit.ticket_pattern": "[A-Z]+-[0-9]+",
+		"commit.language":       "japanese",
+		"vertex_ai.project_id":  "123",
+		"commit.scopes":         "[api, ui]",
+		"commit.signoff":        "true",
+		"ui.max_summary_files":  "10",
+	}
+	for key, value := range values {
+		if err := SetFileValue(path, key, value); err != nil {
+			t.Fatalf("SetFileValue(%q, %q) error = %v", key, value, err)
+		}
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	fileConfig, err := decodeFileConfig(data)
+	if err != nil {
+		t.Fatalf("result does not decode: %v\n%s", err, data)
+	}
+
+	if got := fileConfig.Commit.TicketPattern; got != "[A-Z]+-[0-9]+" {
+		t.Errorf("commit.ticket_pattern = %q", got)
+	}
+	if got := fileConfig.Commit.Language; got != "japanese" {
+		t.Errorf("commit.language = %q", got)
+	}
+	if got := fileConfig.VertexAI.ProjectID; got != "123" {
+		t.Errorf("vertex_ai.project_id = %q", got)
+	}
+	if got := fileConfig.Commit.Scopes; !slices.Equal(got, []string{"api", "ui"}) {
+		t.Errorf("commit.scopes = %q", got)
+	}
+	if !fileConfig.Commit.Signoff {
+		t.Error("commit.signoff is not set")
+	}
+	if got := fileConfig.UI.MaxSummaryFiles; got == nil || *got != 10 {
+		t.Errorf("ui.max_summary_files = %v", got)
+	}
+
+	if value, _, _ := GetFileValue(path, "commit.ticket_pattern"); value != "[A-Z]+-[0-9]+" {
+		t.Errorf("GetFileValue(commit.ticket_pattern) = %q", value)
+	}
+}
+
+func TestSetFileValueInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "gelf.yml")
+	tests := []struct {
+		key   string
+		value string
+	}{
+		{"commit.nope", "x"},
+		{"commit", "x"},
+		{"ui.max_summary_files", "many"},
+		{"commit.scopes", "[api"},
+	}
+	for _, tt := range tests {
+		if err := SetFileValue(path, tt.key, tt.value); err == nil {
+			t.Errorf("SetFileValue(%q, %q) succeeded, want an error", tt.key, tt.value)
+		}
+	}
+}
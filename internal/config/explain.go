@@ -0,0 +1,414 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// Source is one place a configuration value can come from.
+type Source struct {
+	// Kind is one of "flag", "env", "file", "inherit" or "default".
+	Kind string
+	// Name identifies the source, such as an environment variable name, a
+	// config file path and key, or a flag.
+	Name string
+	// Value is the value the source provides, if Set.
+	Value any
+	Set   bool
+}
+
+// Resolution describes how a configuration key was resolved, listing every
+// source in precedence order (highest first).
+type Resolution struct {
+	Key string
+	// Value is the resolved value, typed like the key (string, int,
+	// float32, bool, []string or map[string]ModelPricing).
+	Value   any
+	Sources []Source
+	// Winner is the index in Sources of the source that provided Value, or
+	// -1 when no source set the key.
+	Winner int
+	// Reason explains in one sentence why the winner was chosen.
+	Reason string
+}
+
+// keySpec lists the sources of a key in precedence order. file returns the
+// value in the config file and whether the file sets it; when nothing sets
+// the key, the unset file value is used as the key's zero value.
+type keySpec struct {
+	flags    []string
+	env      []string
+	file     func(*FileConfig) (any, bool)
+	inherit  string
+	fallback any
+}
+
+// keySpecs is the single definition of how each key is resolved. Load reads
+// every value through it, so the trace reported by config explain and
+// config list --json always matches the effective configuration.
+var keySpecs = map[string]keySpec{
+	"backend": {
+		file:     fileString(func(f *FileConfig) string { return f.Backend }),
+		fallback: BackendVertexAI,
+	},
+	"vertex_ai.project_id": {
+		env:  []string{"VERTEXAI_PROJECT", "GOOGLE_CLOUD_PROJECT"},
+		file: fileString(func(f *FileConfig) string { return f.VertexAI.ProjectID }),
+	},
+	"vertex_ai.location": {
+		env:      []string{"VERTEXAI_LOCATION"},
+		file:     fileString(func(f *FileConfig) string { return f.VertexAI.Location }),
+		fallback: "global",
+	},
+	"model.flash": {
+		file:     fileString(func(f *FileConfig) string { return f.Model.Flash }),
+		fallback: DefaultFlashModel,
+	},
+	"model.pro": {
+		file:     fileString(func(f *FileConfig) string { return f.Model.Pro }),
+		fallback: DefaultProModel,
+	},
+	"language": {
+		file:     fileString(func(f *FileConfig) string { return f.Language }),
+		fallback: "english",
+	},
+	"color": {
+		file:     fileString(func(f *FileConfig) string { return f.Color }),
+		fallback: "auto",
+	},
+	"commit.model": {
+		flags:    []string{"gelf commit --model"},
+		file:     fileString(func(f *FileConfig) string { return f.Commit.Model }),
+		fallback: "flash",
+	},
+	"commit.language": {
+		flags:   []string{"gelf commit --language"},
+		file:    fileString(func(f *FileConfig) string { return f.Commit.Language }),
+		inherit: "language",
+	},
+	"commit.ticket_pattern": {
+		file: fileString(func(f *FileConfig) string { return f.Commit.TicketPattern }),
+	},
+	"commit.ticket_placement": {
+		file:     fileString(func(f *FileConfig) string { return f.Commit.TicketPlacement }),
+		fallback: "footer",
+	},
+	"commit.prompt_template": {
+		file: fileString(func(f *FileConfig) string { return f.Commit.PromptTemplate }),
+	},
+	"commit.signoff": {
+		flags:    []string{"gelf commit --signoff"},
+		file:     fileBool(func(f *FileConfig) bool { return f.Commit.Signoff }),
+		fallback: false,
+	},
+	"commit.gitmoji": {
+		flags:    []string{"gelf commit --gitmoji"},
+		file:     fileBool(func(f *FileConfig) bool { return f.Commit.Gitmoji }),
+		fallback: false,
+	},
+	"commit.scopes": {
+		file: fileList(func(f *FileConfig) []string { return f.Commit.Scopes }),
+	},
+	"commit.types": {
+		file: fileList(func(f *FileConfig) []string { return f.Commit.Types }),
+	},
+	"commit.auto_scopes": {
+		file:     fileBool(func(f *FileConfig) bool { return f.Commit.AutoScopes }),
+		fallback: false,
+	},
+	"commit.fallback_format": {
+		file:     fileString(func(f *FileConfig) string { return f.Commit.FallbackFormat }),
+		fallback: DefaultCommitFallbackFormat,
+	},
+	"commit.temperature": {
+		file:     fileFloat(func(f *FileConfig) *float32 { return f.Commit.Temperature }),
+		fallback: float32(0.3),
+	},
+	"commit.max_diff_tokens": {
+		file:     fileInt(func(f *FileConfig) *int { return f.Commit.MaxDiffTokens }),
+		fallback: 200000,
+	},
+	"commit.max_subject_length": {
+		flags:    []string{"gelf commit --max-subject-length"},
+		file:     fileInt(func(f *FileConfig) *int { return f.Commit.MaxSubjectLength }),
+		fallback: 72,
+	},
+	"pr.model": {
+		flags:    []string{"gelf pr create --model"},
+		file:     fileString(func(f *FileConfig) string { return f.PR.Model }),
+		fallback: "pro",
+	},
+	"pr.language": {
+		flags:   []string{"gelf pr create --language"},
+		file:    fileString(func(f *FileConfig) string { return f.PR.Language }),
+		inherit: "language",
+	},
+	"pr.title_language": {
+		flags:   []string{"gelf pr create --title-language"},
+		file:    fileString(func(f *FileConfig) string { return f.PR.TitleLanguage }),
+		inherit: "pr.language",
+	},
+	"pr.body_language": {
+		flags:   []string{"gelf pr create --body-language"},
+		file:    fileString(func(f *FileConfig) string { return f.PR.BodyLanguage }),
+		inherit: "pr.language",
+	},
+	"pr.temperature": {
+		file:     fileFloat(func(f *FileConfig) *float32 { return f.PR.Temperature }),
+		fallback: float32(0.2),
+	},
+	"ui.max_summary_files": {
+		file:     fileInt(func(f *FileConfig) *int { return f.UI.MaxSummaryFiles }),
+		fallback: 20,
+	},
+	"diff.context_lines": {
+		flags:    []string{"gelf commit --context"},
+		file:     fileInt(func(f *FileConfig) *int { return f.Diff.ContextLines }),
+		fallback: 5,
+	},
+	"diff.exclude": {
+		file: fileList(func(f *FileConfig) []string { return f.Diff.Exclude }),
+	},
+	"hooks.on_commit": {
+		file: fileString(func(f *FileConfig) string { return f.Hooks.OnCommit }),
+	},
+	"ai.max_retries": {
+		file:     fileInt(func(f *FileConfig) *int { return f.AI.MaxRetries }),
+		fallback: 3,
+	},
+	"ai.retry_base_delay": {
+		file:     fileString(func(f *FileConfig) string { return f.AI.RetryBaseDelay }),
+		fallback: "1s",
+	},
+	"ai.timeout": {
+		file:     fileString(func(f *FileConfig) string { return f.AI.Timeout }),
+		fallback: "60s",
+	},
+	"ai.redact_paths": {
+		file: fileList(func(f *FileConfig) []string { return f.AI.RedactPaths }),
+	},
+	"pricing": {
+		file: func(f *FileConfig) (any, bool) { return f.Pricing, f.Pricing != nil },
+	},
+}
+
+func fileString(get func(*FileConfig) string) func(*FileConfig) (any, bool) {
+	return func(f *FileConfig) (any, bool) {
+		v := get(f)
+		return v, v != ""
+	}
+}
+
+func fileInt(get func(*FileConfig) *int) func(*FileConfig) (any, bool) {
+	return func(f *FileConfig) (any, bool) {
+		if v := get(f); v != nil {
+			return *v, true
+		}
+		return 0, false
+	}
+}
+
+func fileFloat(get func(*FileConfig) *float32) func(*FileConfig) (any, bool) {
+	return func(f *FileConfig) (any, bool) {
+		if v := get(f); v != nil {
+			return *v, true
+		}
+		return float32(0), false
+	}
+}
+
+// fileBool treats false as unset, since the file cannot tell an explicit
+// false from a missing key.
+func fileBool(get func(*FileConfig) bool) func(*FileConfig) (any, bool) {
+	return func(f *FileConfig) (any, bool) {
+		v := get(f)
+		return v, v
+	}
+}
+
+// fileList treats an explicitly empty list as set, so it can be rejected
+// where an empty list makes no sense.
+func fileList(get func(*FileConfig) []string) func(*FileConfig) (any, bool) {
+	return func(f *FileConfig) (any, bool) {
+		v := get(f)
+		return v, v != nil
+	}
+}
+
+// resolver resolves keys against one config file, remembering each
+// resolution.
+type resolver struct {
+	file        *FileConfig
+	path        string
+	resolutions map[string]*Resolution
+}
+
+func newResolver(file *FileConfig, path string) *resolver {
+	return &resolver{file: file, path: path, resolutions: make(map[string]*Resolution)}
+}
+
+func (r *resolver) resolve(key string) *Resolution {
+	if res, ok := r.resolutions[key]; ok {
+		return res
+	}
+
+	spec, ok := keySpecs[key]
+	if !ok {
+		panic(fmt.Sprintf("config: no key spec for %q", key))
+	}
+	res := &Resolution{Key: key, Value: "", Winner: -1}
+
+	for _, flag := range spec.flags {
+		res.Sources = append(res.Sources, Source{Kind: "flag", Name: flag})
+	}
+	for _, name := range spec.env {
+		value := os.Getenv(name)
+		res.Sources = append(res.Sources, Source{Kind: "env", Name: name, Value: value, Set: value != ""})
+	}
+	if spec.file != nil {
+		name := key + " (no config file found)"
+		if r.path != "" {
+			name = fmt.Sprintf("%s in %s", key, r.path)
+		}
+		value, set := spec.file(r.file)
+		res.Value = value
+		res.Sources = append(res.Sources, Source{Kind: "file", Name: name, Value: value, Set: set})
+	}
+	if spec.inherit != "" {
+		parent := r.resolve(spec.inherit)
+		res.Sources = append(res.Sources, Source{Kind: "inherit", Name: spec.inherit, Value: parent.Value, Set: FormatValue(parent.Value) != ""})
+	}
+	if spec.fallback != nil {
+		res.Sources = append(res.Sources, Source{Kind: "default", Name: "built-in default", Value: spec.fallback, Set: true})
+	}
+
+	for i, source := range res.Sources {
+		if !source.Set {
+			continue
+		}
+		res.Winner = i
+		res.Value = source.Value
+		res.Reason = reasonFor(source, i > 0)
+		break
+	}
+	if res.Winner == -1 {
+		res.Reason = "no source sets this key, so it is empty"
+	}
+
+	r.resolutions[key] = res
+	return res
+}
+
+func (r *resolver) string(key string) string {
+	v, _ := r.resolve(key).Value.(string)
+	return v
+}
+
+func (r *resolver) int(key string) int {
+	v, _ := r.resolve(key).Value.(int)
+	return v
+}
+
+func (r *resolver) float(key string) float32 {
+	v, _ := r.resolve(key).Value.(float32)
+	return v
+}
+
+func (r *resolver) bool(key string) bool {
+	v, _ := r.resolve(key).Value.(bool)
+	return v
+}
+
+func (r *resolver) list(key string) []string {
+	v, _ := r.resolve(key).Value.([]string)
+	return v
+}
+
+// all resolves every key, returning the resolutions by key.
+func (r *resolver) all() map[string]*Resolution {
+	for key := range keySpecs {
+		r.resolve(key)
+	}
+	return r.resolutions
+}
+
+// ExplainableKeys returns the configuration keys Explain understands, sorted.
+func ExplainableKeys() []string {
+	keys := make([]string, 0, len(keySpecs))
+	for key := range keySpecs {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Explain reports every source considered for key and which one provides
+// the effective value, as recorded by Load. Command-line flags are listed
+// for reference but are never set, since they only apply to a single
+// invocation.
+func Explain(key string) (*Resolution, error) {
+	if _, ok := keySpecs[key]; !ok {
+		return nil, fmt.Errorf("unknown configuration key %q (available: %s)", key, strings.Join(ExplainableKeys(), ", "))
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	return cfg.Resolutions[key], nil
+}
+
+// WinningSource returns the source that provided the value, or nil when no
+// source set the key.
+func (r *Resolution) WinningSource() *Source {
+	if r.Winner < 0 {
+		return nil
+	}
+	return &r.Sources[r.Winner]
+}
+
+// FormatValue formats a resolved value for display: lists are joined with
+// commas and unset values are empty.
+func FormatValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case []string:
+		return strings.Join(v, ", ")
+	case map[string]ModelPricing:
+		names := make([]string, 0, len(v))
+		for name := range v {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		parts := make([]string, len(names))
+		for i, name := range names {
+			parts[i] = fmt.Sprintf("%s: %g/M input tokens", name, v[name].InputPerMillion)
+		}
+		return strings.Join(parts, ", ")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func reasonFor(source Source, overridesUnset bool) string {
+	var reason string
+	switch source.Kind {
+	case "env":
+		reason = fmt.Sprintf("environment variable %s is set", source.Name)
+	case "file":
+		reason = "the config file sets it"
+	case "inherit":
+		reason = fmt.Sprintf("it is not set directly, so it inherits %s", source.Name)
+	default:
+		reason = "nothing else sets it, so the built-in default applies"
+	}
+	if overridesUnset && source.Kind != "inherit" && source.Kind != "default" {
+		reason += " and no higher-priority source is set"
+	}
+	return reason
+}
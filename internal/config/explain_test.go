@@ -0,0 +1,139 @@
+package config
+
+import (
+	"os"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// useConfigFile makes Load read content as gelf.yml from a temporary
+// directory, isolated from the user's config files and environment.
+func useConfigFile(t *testing.T, content string) {
+	t.Helper()
+	dir := t.TempDir()
+	t.Chdir(dir)
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	for _, name := range []string{"VERTEXAI_PROJECT", "GOOGLE_CLOUD_PROJECT", "VERTEXAI_LOCATION"} {
+		t.Setenv(name, "")
+	}
+	if content != "" {
+		if err := os.WriteFile("gelf.yml", []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestLoadTraceSetInMultipleSources(t *testing.T) {
+	useConfigFile(t, "vertex_ai:\n  project_id: file-project\n")
+	t.Setenv("GOOGLE_CLOUD_PROJECT", "env-project")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if cfg.ProjectID != "env-project" {
+		t.Errorf("ProjectID = %q, want env-project", cfg.ProjectID)
+	}
+
+	res := cfg.Resolutions["vertex_ai.project_id"]
+	var kinds []string
+	for _, source := range res.Sources {
+		kinds = append(kinds, source.Kind+":"+source.Name+"="+FormatValue(source.Value))
+	}
+	want := []string{
+		"env:VERTEXAI_PROJECT=",
+		"env:GOOGLE_CLOUD_PROJECT=env-project",
+		"file:vertex_ai.project_id in gelf.yml=file-project",
+	}
+	if !slices.Equal(kinds, want) {
+		t.Errorf("sources = %q, want %q", kinds, want)
+	}
+	if res.Winner != 1 {
+		t.Errorf("winner = %d, want 1 (GOOGLE_CLOUD_PROJECT)", res.Winner)
+	}
+	if !strings.Contains(res.Reason, "GOOGLE_CLOUD_PROJECT is set and no higher-priority source is set") {
+		t.Errorf("reason = %q", res.Reason)
+	}
+}
+
+func TestLoadTraceInheritedAndTyped(t *testing.T) {
+	useConfigFile(t, "language: japanese\npr:\n  language: german\ncommit:\n  scopes: [api, ui]\n  max_subject_length: 50\n")
+
+	cfg, err := Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	tests := []struct {
+		key        string
+		wantValue  any
+		wantWinner string
+	}{
+		{"commit.language", "japanese", "inherit"},
+		{"pr.title_language", "german", "inherit"},
+		{"pr.language", "german", "file"},
+		{"commit.scopes", []string{"api", "ui"}, "file"},
+		{"commit.max_subject_length", 50, "file"},
+		{"commit.temperature", float32(0.3), "default"},
+		{"commit.signoff", false, "default"},
+		{"diff.exclude", []string(nil), ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			res := cfg.Resolutions[tt.key]
+			if !reflect.DeepEqual(res.Value, tt.wantValue) {
+				t.Errorf("value = %#v, want %#v", res.Value, tt.wantValue)
+			}
+			winner := ""
+			if source := res.WinningSource(); source != nil {
+				winner = source.Kind
+			}
+			if winner != tt.wantWinner {
+				t.Errorf("winner = %q, want %q", winner, tt.wantWinner)
+			}
+		})
+	}
+
+	if cfg.CommitLanguage != "japanese" || cfg.PRTitleLanguage != "german" || cfg.CommitMaxSubjectLength != 50 {
+		t.Errorf("Config does not match the trace: %q %q %d", cfg.CommitLanguage, cfg.PRTitleLanguage, cfg.CommitMaxSubjectLength)
+	}
+}
+
+func TestExplainUnknownKey(t *testing.T) {
+	useConfigFile(t, "")
+	if _, err := Explain("commit.nope"); err == nil || !strings.Contains(err.Error(), "unknown configuration key") {
+		t.Errorf("Explain() error = %v, want an unknown key error", err)
+	}
+}
+
+// TestKeySpecsCoverFileConfig keeps keySpecs in sync with FileConfig, so
+// every key in gelf.yml can be explained and listed.
+func TestKeySpecsCoverFileConfig(t *testing.T) {
+	var keys []string
+	var walk func(prefix string, typ reflect.Type)
+	walk = func(prefix string, typ reflect.Type) {
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			name, _, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+			key := prefix + name
+			if field.Type.Kind() == reflect.Struct {
+				walk(key+".", field.Type)
+				continue
+			}
+			keys = append(keys, key)
+		}
+	}
+	walk("", reflect.TypeOf(FileConfig{}))
+
+	for _, key := range keys {
+		if _, ok := keySpecs[key]; !ok {
+			t.Errorf("FileConfig key %q has no keySpec", key)
+		}
+	}
+	if len(keySpecs) != len(keys) {
+		t.Errorf("keySpecs has %d keys, FileConfig has %d", len(keySpecs), len(keys))
+	}
+}
@@ -0,0 +1,43 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+)
+
+// PromptPreludePath is the location of the per-repository prompt prelude,
+// relative to the repository root.
+const PromptPreludePath = ".gelf/prompt.md"
+
+// MaxPromptPreludeBytes bounds how much of the prelude is sent to the model.
+const MaxPromptPreludeBytes = 4096
+
+// LoadPromptPrelude reads the prompt prelude from repoRoot. It returns an
+// empty string when the file does not exist. Content larger than
+// MaxPromptPreludeBytes is truncated.
+func LoadPromptPrelude(repoRoot string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(repoRoot, PromptPreludePath))
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	return truncatePrelude(strings.TrimSpace(string(data)), MaxPromptPreludeBytes), nil
+}
+
+func truncatePrelude(content string, limit int) string {
+	if len(content) <= limit {
+		return content
+	}
+
+	cut := limit
+	for cut > 0 && !utf8.RuneStart(content[cut]) {
+		cut--
+	}
+	return strings.TrimSpace(content[:cut]) + "\n[truncated]"
+}
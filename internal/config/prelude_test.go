@@ -0,0 +1,69 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func writePrelude(t *testing.T, root, content string) {
+	t.Helper()
+	path := filepath.Join(root, PromptPreludePath)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoadPromptPreludeMissing(t *testing.T) {
+	prelude, err := LoadPromptPrelude(t.TempDir())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if prelude != "" {
+		t.Errorf("prelude = %q, want empty", prelude)
+	}
+}
+
+func TestLoadPromptPreludePresent(t *testing.T) {
+	root := t.TempDir()
+	writePrelude(t, root, "\nUse the billing domain vocabulary.\n\n")
+
+	prelude, err := LoadPromptPrelude(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if want := "Use the billing domain vocabulary."; prelude != want {
+		t.Errorf("prelude = %q, want %q", prelude, want)
+	}
+}
+
+func TestLoadPromptPreludeTruncated(t *testing.T) {
+	root := t.TempDir()
+	// "é" is two bytes, so an odd prefix puts the byte limit inside a rune
+	content := "x" + strings.Repeat("é", MaxPromptPreludeBytes)
+	writePrelude(t, root, content)
+
+	prelude, err := LoadPromptPrelude(root)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	body, ok := strings.CutSuffix(prelude, "\n[truncated]")
+	if !ok {
+		t.Fatalf("prelude does not end with [truncated]: %q", prelude[len(prelude)-20:])
+	}
+	if len(body) > MaxPromptPreludeBytes {
+		t.Errorf("kept %d bytes, want at most %d", len(body), MaxPromptPreludeBytes)
+	}
+	if !utf8.ValidString(body) {
+		t.Error("truncated prelude is not valid UTF-8")
+	}
+	if !strings.HasPrefix(content, body) {
+		t.Error("truncated prelude is not a prefix of the file content")
+	}
+}
@@ -0,0 +1,82 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ScaffoldOptions are the values pre-filled in a generated gelf.yml.
+type ScaffoldOptions struct {
+	ProjectID  string
+	Location   string
+	Language   string
+	FlashModel string
+	ProModel   string
+}
+
+// Scaffold returns a commented gelf.yml with the given values filled in and
+// every other setting commented out at its default.
+func Scaffold(opts ScaffoldOptions) string {
+	projectID := opts.ProjectID
+	projectIDLine := fmt.Sprintf("  project_id: %q", projectID)
+	if projectID == "" {
+		projectIDLine = `  # project_id: "your-gcp-project-id"`
+	}
+
+	location := defaultString(opts.Location, "global")
+	language := defaultString(opts.Language, "english")
+	flashModel := defaultString(opts.FlashModel, DefaultFlashModel)
+	proModel := defaultString(opts.ProModel, DefaultProModel)
+
+	lines := []string{
+		"# gelf configuration file",
+		"# See https://github.com/EkeMinusYou/gelf for all options.",
+		"",
+		"vertex_ai:",
+		"  # Google Cloud project ID (VERTEXAI_PROJECT / GOOGLE_CLOUD_PROJECT take priority)",
+		projectIDLine,
+		"",
+		"  # Vertex AI region/location (default: global)",
+		fmt.Sprintf("  location: %q", location),
+		"",
+		"# Model definitions",
+		"model:",
+		fmt.Sprintf("  flash: %q", flashModel),
+		fmt.Sprintf("  pro: %q", proModel),
+		"",
+		"# Default language for all operations (default: english)",
+		fmt.Sprintf("language: %q", language),
+		"",
+		`# Color output: "auto", "always" or "never" (default: auto, color only on a terminal and without NO_COLOR)`,
+		`# color: "auto"`,
+		"",
+		"commit:",
+		`  # Model for commit messages: "flash", "pro", or a model name (default: flash)`,
+		`  # model: "flash"`,
+		"",
+		"  # Language for commit messages (inherits from language)",
+		`  # language: "english"`,
+		"",
+		"pr:",
+		`  # Model for pull requests: "flash", "pro", or a model name (default: pro)`,
+		`  # model: "pro"`,
+		"",
+		"  # Language for PR title and body (inherits from language)",
+		`  # language: "english"`,
+		"",
+		"ai:",
+		"  # Retries for transient errors (default: 3)",
+		"  # max_retries: 3",
+		"",
+		"  # Timeout for each AI request (default: 60s)",
+		`  # timeout: "60s"`,
+	}
+	return strings.Join(lines, "\n") + "\n"
+}
+
+func defaultString(value, fallback string) string {
+	if value == "" {
+		return fallback
+	}
+	return value
+}
@@ -0,0 +1,94 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+)
+
+// CommitPromptData is the data passed to a user-supplied commit prompt
+// template (commit.prompt_template).
+type CommitPromptData struct {
+	Diff     string
+	Language string
+	Branch   string
+}
+
+// TemplateVariable describes a variable available to prompt templates.
+type TemplateVariable struct {
+	Name        string
+	Description string
+}
+
+// CommitPromptTemplateVariables lists the variables available to
+// commit.prompt_template.
+func CommitPromptTemplateVariables() []TemplateVariable {
+	return []TemplateVariable{
+		{Name: "{{.Diff}}", Description: "staged git diff"},
+		{Name: "{{.Language}}", Description: "language for the commit message"},
+		{Name: "{{.Branch}}", Description: "current branch name (empty on detached HEAD)"},
+	}
+}
+
+// ParseCommitPromptTemplate parses text as a commit prompt template and checks
+// that it only references known variables.
+func ParseCommitPromptTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("commit_prompt").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, CommitPromptData{}); err != nil {
+		return nil, fmt.Errorf("%w (available variables: %s)", err, variableNames(CommitPromptTemplateVariables()))
+	}
+
+	return tmpl, nil
+}
+
+// DefaultCommitFallbackFormat is the commit.fallback_format used when none
+// is configured.
+const DefaultCommitFallbackFormat = "chore: update {{.Summary}}"
+
+// CommitFallbackData is the data passed to commit.fallback_format, the
+// template for the message used when AI generation fails and
+// --allow-fallback is set.
+type CommitFallbackData struct {
+	Count   int
+	Files   string
+	Summary string
+}
+
+// CommitFallbackTemplateVariables lists the variables available to
+// commit.fallback_format.
+func CommitFallbackTemplateVariables() []TemplateVariable {
+	return []TemplateVariable{
+		{Name: "{{.Count}}", Description: "number of changed files"},
+		{Name: "{{.Files}}", Description: "comma-separated list of changed files"},
+		{Name: "{{.Summary}}", Description: `the file name for a single file, otherwise "N files"`},
+	}
+}
+
+// ParseCommitFallbackTemplate parses text as a fallback commit message
+// template and checks that it only references known variables.
+func ParseCommitFallbackTemplate(text string) (*template.Template, error) {
+	tmpl, err := template.New("commit_fallback").Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, CommitFallbackData{}); err != nil {
+		return nil, fmt.Errorf("%w (available variables: %s)", err, variableNames(CommitFallbackTemplateVariables()))
+	}
+
+	return tmpl, nil
+}
+
+func variableNames(vars []TemplateVariable) string {
+	names := make([]string, len(vars))
+	for i, v := range vars {
+		names[i] = v.Name
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,62 @@
+package config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseCommitFallbackTemplate(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		data    CommitFallbackData
+		want    string
+		wantErr string
+	}{
+		{
+			name: "single file",
+			text: DefaultCommitFallbackFormat,
+			data: CommitFallbackData{Count: 1, Files: "cmd/root.go", Summary: "root.go"},
+			want: "chore: update root.go",
+		},
+		{
+			name: "several files",
+			text: "chore: update {{.Count}} files ({{.Files}})",
+			data: CommitFallbackData{Count: 2, Files: "a.go, b.go", Summary: "2 files"},
+			want: "chore: update 2 files (a.go, b.go)",
+		},
+		{
+			name:    "unknown variable",
+			text:    "chore: {{.Author}}",
+			wantErr: "available variables: {{.Count}}, {{.Files}}, {{.Summary}}",
+		},
+		{
+			name:    "syntax error",
+			text:    "chore: {{.Summary",
+			wantErr: "unclosed action",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpl, err := ParseCommitFallbackTemplate(tt.text)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("ParseCommitFallbackTemplate() error = %v, want it to mention %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseCommitFallbackTemplate() error = %v", err)
+			}
+
+			var sb strings.Builder
+			if err := tmpl.Execute(&sb, tt.data); err != nil {
+				t.Fatal(err)
+			}
+			if got := sb.String(); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Problem is one issue found by Validate.
+type Problem struct {
+	// Warning is true for issues that don't stop gelf from running.
+	Warning bool
+	Message string
+}
+
+// knownLanguages are the languages checked by Validate. Other values still
+// work, since the language is passed to the model verbatim, but are reported
+// as warnings to catch typos.
+var knownLanguages = []string{
+	"english", "japanese", "spanish", "french", "german", "chinese", "korean",
+	"italian", "portuguese", "russian", "dutch", "polish", "turkish", "arabic",
+	"hindi", "indonesian", "vietnamese", "thai", "swedish", "ukrainian",
+}
+
+// Validate checks the active config file and the resulting configuration,
+// returning the path of the file (empty when none was found) and every
+// problem found.
+func Validate() (string, []Problem) {
+	var problems []Problem
+
+	path, ok := FindConfigFile()
+	if ok {
+		problems = append(problems, validateFile(path)...)
+	}
+	for _, p := range problems {
+		if !p.Warning {
+			// Load would fail on the same errors
+			return path, problems
+		}
+	}
+
+	cfg, err := Load()
+	if err != nil {
+		return path, append(problems, Problem{Message: err.Error()})
+	}
+
+	switch cfg.Backend {
+	case BackendVertexAI:
+		if cfg.ProjectID == "" {
+			problems = append(problems, Problem{Message: "vertex_ai.project_id is required for the vertex_ai backend (or set VERTEXAI_PROJECT / GOOGLE_CLOUD_PROJECT)"})
+		}
+	case BackendGemini:
+		if cfg.APIKey == "" {
+			problems = append(problems, Problem{Message: "the gemini backend requires an API key in GELF_API_KEY or GEMINI_API_KEY"})
+		}
+	}
+
+	languages := []struct {
+		key   string
+		value string
+	}{
+		{"commit.language", cfg.CommitLanguage},
+		{"pr.title_language", cfg.PRTitleLanguage},
+		{"pr.body_language", cfg.PRBodyLanguage},
+	}
+	seen := map[string]bool{}
+	for _, l := range languages {
+		if isKnownLanguage(l.value) || seen[l.value] {
+			continue
+		}
+		seen[l.value] = true
+		problems = append(problems, Problem{
+			Warning: true,
+			Message: fmt.Sprintf("%s: unrecognized language %q (known: %s)", l.key, l.value, strings.Join(knownLanguages, ", ")),
+		})
+	}
+
+	return path, problems
+}
+
+// validateFile reports YAML syntax errors and unknown keys in the file at
+// path.
+func validateFile(path string) []Problem {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return []Problem{{Message: fmt.Sprintf("%s: %v", path, err)}}
+	}
+
+	_, err = decodeFileConfig(data)
+	var typeErr *yaml.TypeError
+	switch {
+	case err == nil:
+		return nil
+	case errors.As(err, &typeErr):
+		var problems []Problem
+		for _, msg := range typeErr.Errors {
+			problems = append(problems, Problem{Message: fmt.Sprintf("%s: %s", path, msg)})
+		}
+		return problems
+	default:
+		return []Problem{{Message: fmt.Sprintf("%s: %v", path, err)}}
+	}
+}
+
+func isKnownLanguage(language string) bool {
+	language = strings.ToLower(strings.TrimSpace(language))
+	for _, known := range knownLanguages {
+		if language == known {
+			return true
+		}
+	}
+	return false
+}
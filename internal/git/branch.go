@@ -2,11 +2,25 @@ package git
 
 import (
 	"bufio"
+	"errors"
 	"fmt"
 	"os/exec"
 	"strings"
 )
 
+// ErrNotRepository is returned by EnsureRepo outside a git working tree.
+var ErrNotRepository = errors.New("not inside a git repository (run gelf from a git working tree, or run 'git init' first)")
+
+// EnsureRepo returns ErrNotRepository unless the current directory is inside
+// a git working tree.
+func EnsureRepo() error {
+	output, err := exec.Command("git", "rev-parse", "--is-inside-work-tree").Output()
+	if err != nil || strings.TrimSpace(string(output)) != "true" {
+		return ErrNotRepository
+	}
+	return nil
+}
+
 func GetRepoRoot() (string, error) {
 	cmd := exec.Command("git", "rev-parse", "--show-toplevel")
 	output, err := cmd.Output()
@@ -96,8 +110,9 @@ func remoteShowHeadBranch() (string, error) {
 	return "", fmt.Errorf("HEAD branch not found in origin remote info")
 }
 
-func GetCommittedDiff(baseRef, headRef string) (string, error) {
-	cmd := exec.Command("git", "--no-pager", "diff", "-U5", fmt.Sprintf("%s...%s", baseRef, headRef))
+func GetCommittedDiff(baseRef, headRef string, opts DiffOptions) (string, error) {
+	args := append([]string{"--no-pager", "diff"}, opts.args(fmt.Sprintf("%s...%s", baseRef, headRef))...)
+	cmd := exec.Command("git", args...)
 	output, err := cmd.Output()
 	if err != nil {
 		return "", err
@@ -106,8 +121,9 @@ func GetCommittedDiff(baseRef, headRef string) (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
-func GetCommittedDiffStat(baseRef, headRef string) (string, error) {
-	cmd := exec.Command("git", "--no-pager", "diff", "--stat", fmt.Sprintf("%s...%s", baseRef, headRef))
+func GetCommittedDiffStat(baseRef, headRef string, opts DiffOptions) (string, error) {
+	args := []string{"--no-pager", "diff", "--stat", fmt.Sprintf("%s...%s", baseRef, headRef)}
+	cmd := exec.Command("git", append(args, opts.pathspecs()...)...)
 	output, err := cmd.Output()
 	if err != nil {
 		return "", err
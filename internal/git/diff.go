@@ -1,13 +1,58 @@
 package git
 
 import (
+	"context"
+	"fmt"
 	"os/exec"
 	"regexp"
+	"slices"
 	"strings"
+	"time"
 )
 
-func GetStagedDiff() (string, error) {
-	cmd := exec.Command("git", "--no-pager", "diff", "--staged", "-U5")
+// DiffOptions controls how diffs are generated.
+type DiffOptions struct {
+	// ContextLines is the number of unchanged lines shown around each
+	// change (git diff -U).
+	ContextLines int
+	// Exclude lists globs or git pathspecs whose files are left out of the
+	// diff.
+	Exclude []string
+}
+
+// args returns the git diff arguments for o, placing revs before the
+// pathspecs.
+func (o DiffOptions) args(revs ...string) []string {
+	args := []string{fmt.Sprintf("-U%d", o.ContextLines)}
+	args = append(args, revs...)
+	return append(args, o.pathspecs()...)
+}
+
+func (o DiffOptions) pathspecs() []string {
+	if len(o.Exclude) == 0 {
+		return nil
+	}
+
+	specs := []string{"--"}
+	for _, pattern := range o.Exclude {
+		specs = append(specs, ExcludePathspec(pattern))
+	}
+	return specs
+}
+
+// ExcludePathspec turns pattern into an exclude pathspec matched from the
+// repository root, whatever the current directory. Patterns that already use
+// pathspec magic (":(...)", ":!" or ":^") are passed through.
+func ExcludePathspec(pattern string) string {
+	if strings.HasPrefix(pattern, ":(") || strings.HasPrefix(pattern, ":!") || strings.HasPrefix(pattern, ":^") {
+		return pattern
+	}
+	return ":(top,exclude)" + pattern
+}
+
+func GetStagedDiff(opts DiffOptions) (string, error) {
+	args := append([]string{"--no-pager", "diff", "--staged"}, opts.args()...)
+	cmd := exec.Command("git", args...)
 	output, err := cmd.Output()
 	if err != nil {
 		return "", err
@@ -16,8 +61,9 @@ func GetStagedDiff() (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
-func GetUnstagedDiff() (string, error) {
-	cmd := exec.Command("git", "--no-pager", "diff", "-U5")
+func GetUnstagedDiff(opts DiffOptions) (string, error) {
+	args := append([]string{"--no-pager", "diff"}, opts.args()...)
+	cmd := exec.Command("git", args...)
 	output, err := cmd.Output()
 	if err != nil {
 		return "", err
@@ -26,9 +72,187 @@ func GetUnstagedDiff() (string, error) {
 	return strings.TrimSpace(string(output)), nil
 }
 
-func CommitChanges(message string) error {
-	cmd := exec.Command("git", "commit", "-m", message)
-	return cmd.Run()
+// GetAmendDiff returns the changes the amended commit would contain: the
+// staged changes combined with the changes already committed in HEAD.
+func GetAmendDiff(opts DiffOptions) (string, error) {
+	parent, err := amendBase()
+	if err != nil {
+		return "", err
+	}
+
+	args := append([]string{"--no-pager", "diff", "--staged"}, opts.args(parent)...)
+	cmd := exec.Command("git", args...)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(output)), nil
+}
+
+// GetExcludedFiles returns the files in the staged diff (or the amend diff
+// when amend is set) that opts.Exclude leaves out.
+func GetExcludedFiles(opts DiffOptions, amend bool) ([]string, error) {
+	if len(opts.Exclude) == 0 {
+		return nil, nil
+	}
+
+	var revs []string
+	if amend {
+		parent, err := amendBase()
+		if err != nil {
+			return nil, err
+		}
+		revs = append(revs, parent)
+	}
+
+	all, err := stagedFileNames(revs, nil)
+	if err != nil {
+		return nil, err
+	}
+	kept, err := stagedFileNames(revs, opts.pathspecs())
+	if err != nil {
+		return nil, err
+	}
+
+	var excluded []string
+	for _, name := range all {
+		if !slices.Contains(kept, name) {
+			excluded = append(excluded, name)
+		}
+	}
+	return excluded, nil
+}
+
+// ExcludeFromDiff drops the files matching any of patterns from diff, for
+// diffs that did not come from git diff (such as one read from stdin). It
+// returns the remaining diff and the names of the dropped files. Patterns are
+// the same globs or pathspecs as DiffOptions.Exclude; globs are matched
+// against both the full path and the base name, and a plain path also drops
+// everything below it.
+func ExcludeFromDiff(diff string, patterns []string) (string, []string) {
+	if len(patterns) == 0 || diff == "" {
+		return diff, nil
+	}
+
+	var kept, excluded []string
+	for _, file := range SplitDiff(diff) {
+		header, _, _ := strings.Cut(file, "\n")
+		matches := diffHeaderRegex.FindStringSubmatch(header)
+		if matches != nil && (matchesExclude(matches[1], patterns) || matchesExclude(matches[2], patterns)) {
+			excluded = append(excluded, matches[2])
+			continue
+		}
+		kept = append(kept, file)
+	}
+	return strings.Join(kept, "\n"), excluded
+}
+
+func matchesExclude(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		// Drop pathspec magic such as ":(exclude)" or ":!"
+		if strings.HasPrefix(pattern, ":(") {
+			if _, rest, ok := strings.Cut(pattern, ")"); ok {
+				pattern = rest
+			}
+		} else if strings.HasPrefix(pattern, ":!") || strings.HasPrefix(pattern, ":^") {
+			pattern = pattern[2:]
+		}
+
+		dir := strings.TrimSuffix(pattern, "/")
+		if dir != "" && (name == dir || strings.HasPrefix(name, dir+"/")) {
+			return true
+		}
+		if matchesAnyPath(name, []string{pattern}) {
+			return true
+		}
+	}
+	return false
+}
+
+func stagedFileNames(revs, pathspecs []string) ([]string, error) {
+	args := append([]string{"--no-pager", "diff", "--staged", "--name-only", "-z"}, revs...)
+	output, err := exec.Command("git", append(args, pathspecs...)...).Output()
+	if err != nil {
+		return nil, err
+	}
+	return strings.FieldsFunc(string(output), func(r rune) bool { return r == 0 }), nil
+}
+
+// amendBase returns the revision the amended commit is compared against:
+// HEAD's parent, or the empty tree when HEAD is the root commit.
+func amendBase() (string, error) {
+	if err := exec.Command("git", "rev-parse", "--verify", "--quiet", "HEAD").Run(); err != nil {
+		return "", fmt.Errorf("no commit to amend")
+	}
+
+	parent := "HEAD~1"
+	if err := exec.Command("git", "rev-parse", "--verify", "--quiet", parent).Run(); err != nil {
+		// HEAD is the root commit, so compare against the empty tree
+		output, err := exec.Command("git", "hash-object", "-t", "tree", "/dev/null").Output()
+		if err != nil {
+			return "", fmt.Errorf("failed to resolve empty tree: %w", err)
+		}
+		parent = strings.TrimSpace(string(output))
+	}
+	return parent, nil
+}
+
+// CommitChanges commits the staged changes with message. With noVerify the
+// pre-commit and commit-msg hooks are skipped. Cancelling ctx interrupts
+// git commit and any hooks it is running.
+func CommitChanges(ctx context.Context, message string, noVerify bool) error {
+	return runCommit(ctx, commitArgs(message, noVerify))
+}
+
+// AmendCommit replaces the last commit with the staged changes and message.
+// With noVerify the pre-commit and commit-msg hooks are skipped. Cancelling
+// ctx interrupts git commit and any hooks it is running.
+func AmendCommit(ctx context.Context, message string, noVerify bool) error {
+	return runCommit(ctx, commitArgs(message, noVerify, "--amend"))
+}
+
+// commitWaitDelay is how long git commit may take to exit after being
+// interrupted before it is killed.
+const commitWaitDelay = 5 * time.Second
+
+// runCommit runs git commit, including its output (such as a hook's
+// rejection message) in the error when it fails.
+func runCommit(ctx context.Context, args []string) error {
+	cmd := exec.CommandContext(ctx, "git", args...)
+	// Interrupt rather than kill, so git removes its index lock
+	interruptOnCancel(cmd)
+	cmd.WaitDelay = commitWaitDelay
+	output, err := cmd.CombinedOutput()
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err != nil {
+		if detail := strings.TrimSpace(string(output)); detail != "" {
+			return fmt.Errorf("%w\n%s", err, detail)
+		}
+		return err
+	}
+	return nil
+}
+
+func commitArgs(message string, noVerify bool, extra ...string) []string {
+	args := append([]string{"commit"}, extra...)
+	if noVerify {
+		args = append(args, "--no-verify")
+	}
+	return append(args, "-m", message)
+}
+
+// HeadMessage returns the full commit message of HEAD.
+func HeadMessage() (string, error) {
+	cmd := exec.Command("git", "log", "-1", "--format=%B")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(output)), nil
 }
 
 type DiffSummary struct {
@@ -39,12 +263,34 @@ type FileDiff struct {
 	Name         string
 	AddedLines   int
 	DeletedLines int
+	// Renamed and Copied are set when git detected a rename or copy, in
+	// which case OldName holds the source path and Name the new path.
+	Renamed bool
+	Copied  bool
+	OldName string
+	// Binary is set for binary files, which have no line counts.
+	Binary bool
+}
+
+// DisplayName returns the file name, shown as "old → new" for renames and
+// "old → new (copy)" for copies.
+func (f FileDiff) DisplayName() string {
+	if (f.Renamed || f.Copied) && f.OldName != "" {
+		name := f.OldName + " → " + f.Name
+		if f.Copied {
+			name += " (copy)"
+		}
+		return name
+	}
+	return f.Name
 }
 
 func ParseDiffSummary(diff string) DiffSummary {
 	summary := DiffSummary{Files: []FileDiff{}}
 
 	fileRegex := regexp.MustCompile(`^diff --git a/(.*) b/(.*)$`)
+	renameRegex := regexp.MustCompile(`^(rename|copy) (from|to) (.*)$`)
+	binaryRegex := regexp.MustCompile(`^Binary files .* differ$`)
 	addedRegex := regexp.MustCompile(`^\+[^+].*$`)
 	deletedRegex := regexp.MustCompile(`^-[^-].*$`)
 
@@ -61,6 +307,21 @@ func ParseDiffSummary(diff string) DiffSummary {
 				AddedLines:   0,
 				DeletedLines: 0,
 			}
+		} else if matches := renameRegex.FindStringSubmatch(line); matches != nil && currentFile != nil {
+			// Extended header lines name the exact paths, which the
+			// "diff --git" line cannot split unambiguously
+			if matches[1] == "rename" {
+				currentFile.Renamed = true
+			} else {
+				currentFile.Copied = true
+			}
+			if matches[2] == "from" {
+				currentFile.OldName = matches[3]
+			} else {
+				currentFile.Name = matches[3]
+			}
+		} else if currentFile != nil && binaryRegex.MatchString(line) {
+			currentFile.Binary = true
 		} else if currentFile != nil {
 			if addedRegex.MatchString(line) {
 				currentFile.AddedLines++
@@ -76,3 +337,21 @@ func ParseDiffSummary(diff string) DiffSummary {
 
 	return summary
 }
+
+// SplitDiff splits a multi-file diff into one diff per file, keeping each
+// file's headers with its hunks.
+func SplitDiff(diff string) []string {
+	var files []string
+	var current []string
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "diff --git ") && len(current) > 0 {
+			files = append(files, strings.Join(current, "\n"))
+			current = nil
+		}
+		current = append(current, line)
+	}
+	if len(current) > 0 && strings.TrimSpace(strings.Join(current, "\n")) != "" {
+		files = append(files, strings.Join(current, "\n"))
+	}
+	return files
+}
@@ -0,0 +1,213 @@
+package git
+
+import (
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestExcludeFromDiff(t *testing.T) {
+	goDiff := diffLines(
+		"diff --git a/main.go b/main.go",
+		"--- a/main.go",
+		"+++ b/main.go",
+		"@@ -1 +1 @@",
+		"-package old",
+		"+package main",
+	)
+	sumDiff := diffLines(
+		"diff --git a/go.sum b/go.sum",
+		"--- a/go.sum",
+		"+++ b/go.sum",
+		"@@ -1 +1 @@",
+		"+example.com/x v1.0.0 h1:abc",
+	)
+	pbDiff := diffLines(
+		"diff --git a/api/v1/api.pb.go b/api/v1/api.pb.go",
+		"--- a/api/v1/api.pb.go",
+		"+++ b/api/v1/api.pb.go",
+		"@@ -1 +1 @@",
+		"+// Code generated. DO NOT EDIT.",
+	)
+	vendorDiff := diffLines(
+		"diff --git a/vendor/x/x.go b/vendor/x/x.go",
+		"--- a/vendor/x/x.go",
+		"+++ b/vendor/x/x.go",
+		"@@ -1 +1 @@",
+		"+package x",
+	)
+	all := diffLines(goDiff, sumDiff, pbDiff, vendorDiff)
+
+	tests := []struct {
+		name         string
+		patterns     []string
+		want         string
+		wantExcluded []string
+	}{
+		{
+			name: "no patterns keeps the diff",
+			want: all,
+		},
+		{
+			name:         "plain file name",
+			patterns:     []string{"go.sum"},
+			want:         diffLines(goDiff, pbDiff, vendorDiff),
+			wantExcluded: []string{"go.sum"},
+		},
+		{
+			name:         "basename glob",
+			patterns:     []string{"*.pb.go"},
+			want:         diffLines(goDiff, sumDiff, vendorDiff),
+			wantExcluded: []string{"api/v1/api.pb.go"},
+		},
+		{
+			name:         "directory",
+			patterns:     []string{"vendor/"},
+			want:         diffLines(goDiff, sumDiff, pbDiff),
+			wantExcluded: []string{"vendor/x/x.go"},
+		},
+		{
+			name:         "pathspec magic is stripped",
+			patterns:     []string{":(exclude)go.sum", ":!vendor"},
+			want:         diffLines(goDiff, pbDiff),
+			wantExcluded: []string{"go.sum", "vendor/x/x.go"},
+		},
+		{
+			name:         "everything excluded",
+			patterns:     []string{"*"},
+			want:         "",
+			wantExcluded: []string{"main.go", "go.sum", "api/v1/api.pb.go", "vendor/x/x.go"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, excluded := ExcludeFromDiff(all, tt.patterns)
+			if got != tt.want {
+				t.Errorf("ExcludeFromDiff() diff =\n%s\nwant\n%s", got, tt.want)
+			}
+			if !slices.Equal(excluded, tt.wantExcluded) {
+				t.Errorf("ExcludeFromDiff() excluded = %q, want %q", excluded, tt.wantExcluded)
+			}
+		})
+	}
+}
+
+func TestExcludeFromSubdirectory(t *testing.T) {
+	root := initRepo(t)
+	writeFile(t, "top.txt", "top\n")
+	writeFile(t, "sub/x/f.txt", "f\n")
+	writeFile(t, "sub/keep.go", "package sub\n")
+	runGit(t, "add", "-A")
+	t.Chdir(filepath.Join(root, "sub"))
+
+	opts := DiffOptions{Exclude: []string{"top.txt", "sub/x/f.txt"}}
+
+	diff, err := GetStagedDiff(opts)
+	if err != nil {
+		t.Fatalf("GetStagedDiff() error = %v", err)
+	}
+	var names []string
+	for _, file := range ParseDiffSummary(diff).Files {
+		names = append(names, file.Name)
+	}
+	if want := []string{"sub/keep.go"}; !slices.Equal(names, want) {
+		t.Errorf("GetStagedDiff() files = %q, want %q", names, want)
+	}
+	if strings.Contains(diff, "top.txt") {
+		t.Errorf("GetStagedDiff() still contains top.txt:\n%s", diff)
+	}
+
+	excluded, err := GetExcludedFiles(opts, false)
+	if err != nil {
+		t.Fatalf("GetExcludedFiles() error = %v", err)
+	}
+	if want := []string{"sub/x/f.txt", "top.txt"}; !slices.Equal(excluded, want) {
+		t.Errorf("GetExcludedFiles() = %q, want %q", excluded, want)
+	}
+}
+
+func TestSplitDiffExtendedHeaders(t *testing.T) {
+	rename := diffLines(
+		"diff --git a/old.go b/new.go",
+		"similarity index 90%",
+		"rename from old.go",
+		"rename to new.go",
+		"index 1111111..2222222 100644",
+		"--- a/old.go",
+		"+++ b/new.go",
+		"@@ -1 +1 @@",
+		"-package old",
+		"+package new",
+	)
+	pureRename := diffLines(
+		"diff --git a/a.txt b/docs/a.txt",
+		"similarity index 100%",
+		"rename from a.txt",
+		"rename to docs/a.txt",
+	)
+	cp := diffLines(
+		"diff --git a/tmpl.go b/tmpl_copy.go",
+		"similarity index 95%",
+		"copy from tmpl.go",
+		"copy to tmpl_copy.go",
+		"index 3333333..4444444 100644",
+		"--- a/tmpl.go",
+		"+++ b/tmpl_copy.go",
+		"@@ -1 +1,2 @@",
+		" package tmpl",
+		"+// copy",
+	)
+	binary := diffLines(
+		"diff --git a/logo.png b/logo.png",
+		"index 5555555..6666666 100644",
+		"Binary files a/logo.png and b/logo.png differ",
+	)
+
+	files := SplitDiff(diffLines(rename, pureRename, cp, binary))
+	if want := []string{rename, pureRename, cp, binary}; !slices.Equal(files, want) {
+		t.Fatalf("SplitDiff() = %q, want %q", files, want)
+	}
+
+	tests := []struct {
+		diff    string
+		want    FileDiff
+		display string
+	}{
+		{
+			diff:    rename,
+			want:    FileDiff{Name: "new.go", OldName: "old.go", Renamed: true, AddedLines: 1, DeletedLines: 1},
+			display: "old.go → new.go",
+		},
+		{
+			diff:    pureRename,
+			want:    FileDiff{Name: "docs/a.txt", OldName: "a.txt", Renamed: true},
+			display: "a.txt → docs/a.txt",
+		},
+		{
+			diff:    cp,
+			want:    FileDiff{Name: "tmpl_copy.go", OldName: "tmpl.go", Copied: true, AddedLines: 1},
+			display: "tmpl.go → tmpl_copy.go (copy)",
+		},
+		{
+			diff:    binary,
+			want:    FileDiff{Name: "logo.png", Binary: true},
+			display: "logo.png",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.display, func(t *testing.T) {
+			summary := ParseDiffSummary(tt.diff)
+			if len(summary.Files) != 1 {
+				t.Fatalf("ParseDiffSummary() found %d files, want 1", len(summary.Files))
+			}
+			if got := summary.Files[0]; got != tt.want {
+				t.Errorf("ParseDiffSummary() = %+v, want %+v", got, tt.want)
+			}
+			if got := summary.Files[0].DisplayName(); got != tt.display {
+				t.Errorf("DisplayName() = %q, want %q", got, tt.display)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// GetHooksDir returns the absolute path of the repository's hooks directory,
+// honoring core.hooksPath and linked worktrees.
+func GetHooksDir() (string, error) {
+	cmd := exec.Command("git", "rev-parse", "--git-path", "hooks")
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to get hooks directory: %w", err)
+	}
+
+	dir := strings.TrimSpace(string(output))
+	if dir == "" {
+		return "", fmt.Errorf("hooks directory is empty")
+	}
+	return filepath.Abs(dir)
+}
@@ -0,0 +1,36 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// UserIdentity returns the user.name and user.email configured in git.
+func UserIdentity() (string, string, error) {
+	name, err := configValue("user.name")
+	if err != nil {
+		return "", "", err
+	}
+	email, err := configValue("user.email")
+	if err != nil {
+		return "", "", err
+	}
+
+	return name, email, nil
+}
+
+func configValue(key string) (string, error) {
+	cmd := exec.Command("git", "config", "--get", key)
+	output, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("git config %s is not set", key)
+	}
+
+	value := strings.TrimSpace(string(output))
+	if value == "" {
+		return "", fmt.Errorf("git config %s is empty", key)
+	}
+
+	return value, nil
+}
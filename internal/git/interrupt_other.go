@@ -0,0 +1,15 @@
+//go:build !unix
+
+package git
+
+import (
+	"os"
+	"os/exec"
+)
+
+// interruptOnCancel makes cancelling cmd's context interrupt cmd.
+func interruptOnCancel(cmd *exec.Cmd) {
+	cmd.Cancel = func() error {
+		return cmd.Process.Signal(os.Interrupt)
+	}
+}
@@ -0,0 +1,18 @@
+//go:build unix
+
+package git
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// interruptOnCancel makes cancelling cmd's context send SIGINT to cmd and
+// every process it started (such as git hooks), like pressing ctrl+c in a
+// terminal would.
+func interruptOnCancel(cmd *exec.Cmd) {
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGINT)
+	}
+}
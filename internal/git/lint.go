@@ -0,0 +1,88 @@
+package git
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// ConventionalTypes are the commit types used when no custom list is
+// configured.
+var ConventionalTypes = []string{"feat", "fix", "docs", "style", "refactor", "test", "chore", "perf", "ci", "build", "revert"}
+
+// generatedPrefixes start messages written by git itself, which are not
+// expected to follow Conventional Commits.
+var generatedPrefixes = []string{"Merge ", "Revert \"", "fixup! ", "squash! ", "amend! "}
+
+// ValidateConventionalCommit checks message against the Conventional Commits
+// rules gelf generates for, allowing the given types (ConventionalTypes when
+// empty). The returned error joins one error per rule violated, or is nil
+// when the message is valid. Messages git generates for merges, reverts and
+// autosquash are accepted as is.
+func ValidateConventionalCommit(message string, types []string) error {
+	if len(types) == 0 {
+		types = ConventionalTypes
+	}
+
+	message = strings.TrimSpace(message)
+	if message == "" {
+		return errors.New("message is empty")
+	}
+	for _, prefix := range generatedPrefixes {
+		if strings.HasPrefix(message, prefix) {
+			return nil
+		}
+	}
+
+	subject, body, hasBody := strings.Cut(message, "\n")
+	_, rest := splitSubjectPrefix(subject)
+	matches := subjectRegex.FindStringSubmatch(rest)
+	if matches == nil {
+		return fmt.Errorf("subject %q does not match <type>[(scope)][!]: <description>", subject)
+	}
+
+	var problems []error
+	if !slices.Contains(types, matches[1]) {
+		problems = append(problems, fmt.Errorf("type %q is not one of %s", matches[1], strings.Join(types, ", ")))
+	}
+	if matches[2] == "" && strings.Contains(rest, "()") {
+		problems = append(problems, errors.New("scope is empty; omit the parentheses"))
+	}
+
+	description := matches[4]
+	if strings.TrimSpace(description) == "" {
+		problems = append(problems, errors.New("description is empty"))
+	} else {
+		if first, _ := utf8.DecodeRuneInString(description); unicode.IsUpper(first) {
+			problems = append(problems, errors.New("description must start with a lowercase letter"))
+		}
+		if strings.HasSuffix(description, ".") {
+			problems = append(problems, errors.New("subject must not end with a period"))
+		}
+	}
+
+	if hasBody && strings.TrimSpace(strings.SplitN(body, "\n", 2)[0]) != "" {
+		problems = append(problems, errors.New("subject must be followed by a blank line"))
+	}
+
+	return errors.Join(problems...)
+}
+
+// StripComments removes the comment lines git adds to a commit message file,
+// along with everything below a "git commit --verbose" scissors line.
+func StripComments(message string) string {
+	var lines []string
+	for _, line := range strings.Split(message, "\n") {
+		if strings.HasPrefix(line, "# ------------------------ >8 ------------------------") {
+			break
+		}
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	return strings.TrimSpace(strings.Join(lines, "\n"))
+}
@@ -0,0 +1,150 @@
+package git
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestValidateConventionalCommit(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		types   []string
+		want    []string
+	}{
+		{
+			name:    "valid message with body",
+			message: "feat(api): add pagination\n\nPages hold 50 items.",
+		},
+		{
+			name:    "unknown type",
+			message: "feature: add pagination",
+			want:    []string{`type "feature" is not one of feat, fix, docs, style, refactor, test, chore, perf, ci, build, revert`},
+		},
+		{
+			name:    "custom types",
+			message: "security: rotate keys",
+			types:   []string{"security", "feat"},
+		},
+		{
+			name:    "default type rejected by custom types",
+			message: "fix: rotate keys",
+			types:   []string{"security", "feat"},
+			want:    []string{`type "fix" is not one of security, feat`},
+		},
+		{
+			name:    "scope with path characters",
+			message: "fix(internal/git): handle empty diffs",
+		},
+		{
+			name:    "empty scope",
+			message: "fix(): handle empty diffs",
+			want:    []string{"scope is empty; omit the parentheses"},
+		},
+		{
+			name:    "unclosed scope",
+			message: "fix(git: handle empty diffs",
+			want:    []string{`subject "fix(git: handle empty diffs" does not match <type>[(scope)][!]: <description>`},
+		},
+		{
+			name:    "breaking marker",
+			message: "feat(api)!: drop v1\n\nBREAKING CHANGE: use /v2",
+		},
+		{
+			name:    "breaking marker without scope",
+			message: "feat!: drop v1",
+		},
+		{
+			name:    "uppercase description",
+			message: "fix: Handle empty diffs",
+			want:    []string{"description must start with a lowercase letter"},
+		},
+		{
+			name:    "trailing period",
+			message: "fix: handle empty diffs.",
+			want:    []string{"subject must not end with a period"},
+		},
+		{
+			name:    "several problems",
+			message: "fix: Handle empty diffs.",
+			want:    []string{"description must start with a lowercase letter", "subject must not end with a period"},
+		},
+		{
+			name:    "body without blank line",
+			message: "fix: handle empty diffs\nThe TUI crashed.",
+			want:    []string{"subject must be followed by a blank line"},
+		},
+		{
+			name:    "missing description",
+			message: "fix: ",
+			want:    []string{`subject "fix:" does not match <type>[(scope)][!]: <description>`},
+		},
+		{
+			name:    "empty message",
+			message: "  \n",
+			want:    []string{"message is empty"},
+		},
+		{
+			name:    "gitmoji prefix",
+			message: "🐛 fix: handle empty diffs",
+		},
+		{
+			name:    "merge commit",
+			message: "Merge branch 'main' into feature",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateConventionalCommit(tt.message, tt.types)
+			var got []string
+			if err != nil {
+				got = strings.Split(err.Error(), "\n")
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("ValidateConventionalCommit() problems = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStripComments(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{
+			name:    "comment lines",
+			message: "fix: handle empty diffs\n\n# Please enter the commit message.\n#\n# On branch main\n",
+			want:    "fix: handle empty diffs",
+		},
+		{
+			name:    "body kept around comments",
+			message: "fix: handle empty diffs\n# comment\n\nThe TUI crashed.\n",
+			want:    "fix: handle empty diffs\n\nThe TUI crashed.",
+		},
+		{
+			name: "scissors line drops the verbose diff",
+			message: "feat: add --amend\n\n" +
+				"# ------------------------ >8 ------------------------\n" +
+				"# Do not modify or remove the line above.\n" +
+				"diff --git a/x.go b/x.go\n+added line\n",
+			want: "feat: add --amend",
+		},
+		{
+			name:    "only comments",
+			message: "# nothing here\n",
+			want:    "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := StripComments(tt.message); got != tt.want {
+				t.Errorf("StripComments() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,316 @@
+package git
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+var subjectRegex = regexp.MustCompile(`^(\w+)(?:\(([^)]*)\))?(!)?: (.*)$`)
+
+// splitSubjectPrefix separates a leading emoji (such as a gitmoji) from the
+// Conventional Commits part of a subject line.
+func splitSubjectPrefix(subject string) (string, string) {
+	if subject == "" || subject[0] < utf8.RuneSelf {
+		return "", subject
+	}
+	if idx := strings.Index(subject, " "); idx != -1 {
+		return subject[:idx+1], subject[idx+1:]
+	}
+	return "", subject
+}
+
+// ExtractTicket returns the ticket reference found in branch using pattern.
+// If the pattern has a capture group, the first group is used as the ticket.
+func ExtractTicket(branch, pattern string) (string, error) {
+	if strings.TrimSpace(pattern) == "" {
+		return "", nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return "", fmt.Errorf("invalid ticket pattern %q: %w", pattern, err)
+	}
+
+	matches := re.FindStringSubmatch(branch)
+	if matches == nil {
+		return "", nil
+	}
+	if len(matches) > 1 && matches[1] != "" {
+		return matches[1], nil
+	}
+	return matches[0], nil
+}
+
+// ApplyTicket adds ticket to message either as a "Refs:" footer or as the
+// Conventional Commits scope. Messages that already have a scope fall back
+// to the footer so the existing scope is preserved.
+func ApplyTicket(message, ticket, placement string) string {
+	message = strings.TrimSpace(message)
+	if ticket == "" || strings.Contains(message, ticket) {
+		return message
+	}
+
+	if placement == "scope" {
+		subject, body, _ := strings.Cut(message, "\n")
+		prefix, rest := splitSubjectPrefix(subject)
+		if matches := subjectRegex.FindStringSubmatch(rest); matches != nil && matches[2] == "" {
+			subject = fmt.Sprintf("%s%s(%s)%s: %s", prefix, matches[1], ticket, matches[3], matches[4])
+			if body == "" {
+				return subject
+			}
+			return subject + "\n" + body
+		}
+	}
+
+	return AppendFooter(message, "Refs: "+ticket)
+}
+
+// SetScope replaces the Conventional Commits scope of the subject with scope,
+// adding one if the subject has none.
+func SetScope(message, scope string) string {
+	return rewriteScope(message, func(string) string { return scope })
+}
+
+// RestrictScope removes the scope from the subject when it is not one of
+// allowed. Messages are returned unchanged when allowed is empty.
+func RestrictScope(message string, allowed []string) string {
+	if len(allowed) == 0 {
+		return message
+	}
+	return rewriteScope(message, func(scope string) string {
+		if slices.Contains(allowed, scope) {
+			return scope
+		}
+		return ""
+	})
+}
+
+// MessageScope returns the Conventional Commits scope of the subject, or an
+// empty string when it has none.
+func MessageScope(message string) string {
+	subject, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	_, rest := splitSubjectPrefix(subject)
+	if matches := subjectRegex.FindStringSubmatch(rest); matches != nil {
+		return matches[2]
+	}
+	return ""
+}
+
+func rewriteScope(message string, rewrite func(string) string) string {
+	subject, body, hasBody := strings.Cut(strings.TrimSpace(message), "\n")
+	prefix, rest := splitSubjectPrefix(subject)
+	matches := subjectRegex.FindStringSubmatch(rest)
+	if matches == nil {
+		return message
+	}
+
+	scope := rewrite(matches[2])
+	if scope == matches[2] {
+		return message
+	}
+	if scope != "" {
+		scope = "(" + scope + ")"
+	}
+
+	subject = fmt.Sprintf("%s%s%s%s: %s", prefix, matches[1], scope, matches[3], matches[4])
+	if !hasBody {
+		return subject
+	}
+	return subject + "\n" + body
+}
+
+var gitmojiByType = map[string]string{
+	"feat":     "✨",
+	"fix":      "🐛",
+	"docs":     "📝",
+	"style":    "🎨",
+	"refactor": "♻️",
+	"perf":     "⚡️",
+	"test":     "✅",
+	"build":    "📦",
+	"ci":       "👷",
+	"chore":    "🔧",
+	"revert":   "⏪",
+}
+
+// GitmojiWidth is the number of subject characters reserved for a gitmoji
+// prefix and the following space.
+const GitmojiWidth = 3
+
+// ApplyGitmoji prefixes the subject with the gitmoji matching its
+// Conventional Commits type. Messages with an unknown type or an existing
+// prefix are returned unchanged.
+func ApplyGitmoji(message string) string {
+	subject, body, hasBody := strings.Cut(strings.TrimSpace(message), "\n")
+	if prefix, _ := splitSubjectPrefix(subject); prefix != "" {
+		return message
+	}
+	matches := subjectRegex.FindStringSubmatch(subject)
+	if matches == nil {
+		return message
+	}
+
+	emoji, ok := gitmojiByType[strings.ToLower(matches[1])]
+	if !ok {
+		return message
+	}
+
+	subject = emoji + " " + subject
+	if !hasBody {
+		return subject
+	}
+	return subject + "\n" + body
+}
+
+var breakingFooterRegex = regexp.MustCompile(`(?i)^\s*breaking[ -]changes?\s*:\s*(.*)$`)
+
+// NormalizeBreakingChange rewrites loosely formatted breaking-change notes
+// into a Conventional Commits "BREAKING CHANGE:" footer, separated from the
+// subject by a blank line, and marks the subject with "!".
+func NormalizeBreakingChange(message string) string {
+	lines := strings.Split(strings.TrimSpace(message), "\n")
+	var rest []string
+	var notes []string
+	for i, line := range lines {
+		if i > 0 {
+			if matches := breakingFooterRegex.FindStringSubmatch(line); matches != nil {
+				if note := strings.TrimSpace(matches[1]); note != "" {
+					notes = append(notes, note)
+				}
+				continue
+			}
+		}
+		rest = append(rest, line)
+	}
+	if len(notes) == 0 {
+		return message
+	}
+
+	prefix, subject := splitSubjectPrefix(rest[0])
+	if matches := subjectRegex.FindStringSubmatch(subject); matches != nil && matches[3] == "" {
+		scope := ""
+		if matches[2] != "" {
+			scope = "(" + matches[2] + ")"
+		}
+		rest[0] = fmt.Sprintf("%s%s%s!: %s", prefix, matches[1], scope, matches[4])
+	}
+
+	result := strings.TrimRight(strings.Join(rest, "\n"), "\n ")
+	return result + "\n\nBREAKING CHANGE: " + strings.Join(notes, " ")
+}
+
+// Finalizer adds the ticket reference and sign-off to generated commit
+// messages.
+type Finalizer struct {
+	// Ticket is the ticket reference detected from the current branch.
+	Ticket string
+	// TicketPlacement is either "footer" or "scope".
+	TicketPlacement string
+	// Signoff is a "Signed-off-by" trailer appended after all other footers.
+	Signoff string
+}
+
+// NewFinalizer detects the ticket in branch with ticketPattern (none when
+// either is empty) and, when signoff is set, builds the sign-off trailer from
+// the git user.name and user.email.
+func NewFinalizer(branch, ticketPattern, ticketPlacement string, signoff bool) (Finalizer, error) {
+	f := Finalizer{TicketPlacement: ticketPlacement}
+	if branch != "" {
+		ticket, err := ExtractTicket(branch, ticketPattern)
+		if err != nil {
+			return Finalizer{}, err
+		}
+		f.Ticket = ticket
+	}
+
+	if signoff {
+		name, email, err := UserIdentity()
+		if err != nil {
+			return Finalizer{}, fmt.Errorf("failed to determine identity for sign-off: %w", err)
+		}
+		f.Signoff = SignoffTrailer(name, email)
+	}
+	return f, nil
+}
+
+// Finalize applies the ticket reference and trailers to message.
+func (f Finalizer) Finalize(message string) string {
+	message = ApplyTicket(message, f.Ticket, f.TicketPlacement)
+	if f.Signoff != "" {
+		message = AppendFooter(message, f.Signoff)
+	}
+	return message
+}
+
+// SignoffTrailer formats a DCO "Signed-off-by" trailer.
+func SignoffTrailer(name, email string) string {
+	return fmt.Sprintf("Signed-off-by: %s <%s>", name, email)
+}
+
+// AppendFooter appends a footer line to message, separating it from the
+// subject and body with a blank line unless the message already ends in a
+// footer block.
+func AppendFooter(message, footer string) string {
+	message = strings.TrimRight(message, "\n ")
+	if message == "" {
+		return footer
+	}
+
+	lines := strings.Split(message, "\n")
+	for _, line := range lines {
+		if strings.TrimSpace(line) == footer {
+			return message
+		}
+	}
+
+	if len(lines) > 1 && isFooterLine(lines[len(lines)-1]) {
+		return message + "\n" + footer
+	}
+	return message + "\n\n" + footer
+}
+
+var footerRegex = regexp.MustCompile(`^([\w-]+|BREAKING CHANGE): .+$|^[\w-]+ #.+$`)
+
+func isFooterLine(line string) bool {
+	return footerRegex.MatchString(strings.TrimSpace(line))
+}
+
+// SubjectLength returns the number of characters in the subject line of
+// message.
+func SubjectLength(message string) int {
+	subject, _, _ := strings.Cut(strings.TrimSpace(message), "\n")
+	return utf8.RuneCountInString(subject)
+}
+
+// TruncateSubject shortens the subject line of message to at most maxLength
+// characters, cutting at the last word boundary that fits. The body is kept.
+func TruncateSubject(message string, maxLength int) string {
+	message = strings.TrimSpace(message)
+	if maxLength <= 0 || SubjectLength(message) <= maxLength {
+		return message
+	}
+
+	subject, body, hasBody := strings.Cut(message, "\n")
+	runes := []rune(subject)
+	// Never cut inside the "type(scope): " prefix
+	prefixLength := 0
+	if idx := strings.Index(subject, ": "); idx != -1 {
+		prefixLength = utf8.RuneCountInString(subject[:idx+2])
+	}
+	cut := maxLength
+	for i := maxLength; i > prefixLength; i-- {
+		if runes[i] == ' ' {
+			cut = i
+			break
+		}
+	}
+	subject = strings.TrimRight(string(runes[:cut]), " ,;:-")
+
+	if hasBody {
+		return subject + "\n" + body
+	}
+	return subject
+}
@@ -0,0 +1,247 @@
+package git
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNormalizeBreakingChange(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		want    string
+	}{
+		{
+			name:    "loose breaking change line",
+			message: "feat(config): rename model.default\nBreaking change: use model.flash instead",
+			want:    "feat(config)!: rename model.default\n\nBREAKING CHANGE: use model.flash instead",
+		},
+		{
+			name:    "subject already marked with !",
+			message: "feat!: drop the v1 API\n\nBREAKING-CHANGE: migrate to /v2",
+			want:    "feat!: drop the v1 API\n\nBREAKING CHANGE: migrate to /v2",
+		},
+		{
+			name:    "gitmoji prefix",
+			message: "✨ feat(api): remove the legacy endpoint\n\nbreaking changes: call /items instead",
+			want:    "✨ feat(api)!: remove the legacy endpoint\n\nBREAKING CHANGE: call /items instead",
+		},
+		{
+			name:    "non-breaking message",
+			message: "fix(ui): align the spinner\n\nThe spinner jumped when the message changed.",
+			want:    "fix(ui): align the spinner\n\nThe spinner jumped when the message changed.",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeBreakingChange(tt.message); got != tt.want {
+				t.Errorf("NormalizeBreakingChange(%q)\n got %q\nwant %q", tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetScope(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		scope   string
+		want    string
+	}{
+		{"adds a scope", "feat: add login", "auth", "feat(auth): add login"},
+		{"replaces a scope", "fix(api)!: drop v1\n\nBody", "server", "fix(server)!: drop v1\n\nBody"},
+		{"keeps a gitmoji prefix", "✨ feat(ui): add picker", "cli", "✨ feat(cli): add picker"},
+		{"non-conventional message", "Update readme", "docs", "Update readme"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SetScope(tt.message, tt.scope); got != tt.want {
+				t.Errorf("SetScope(%q, %q) = %q, want %q", tt.message, tt.scope, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRestrictScope(t *testing.T) {
+	allowed := []string{"api", "ui"}
+	tests := []struct {
+		name    string
+		message string
+		allowed []string
+		want    string
+	}{
+		{"allowed scope", "feat(api): add pagination", allowed, "feat(api): add pagination"},
+		{"disallowed scope", "feat(db): add index\n\nBody", allowed, "feat: add index\n\nBody"},
+		{"disallowed scope with !", "refactor(core)!: rename config", allowed, "refactor!: rename config"},
+		{"no scope", "fix: typo", allowed, "fix: typo"},
+		{"empty allowlist", "feat(db): add index", nil, "feat(db): add index"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RestrictScope(tt.message, tt.allowed); got != tt.want {
+				t.Errorf("RestrictScope(%q) = %q, want %q", tt.message, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTruncateSubject(t *testing.T) {
+	tests := []struct {
+		name    string
+		message string
+		limit   int
+		want    string
+	}{
+		{
+			name:    "zero limit disables truncation",
+			message: "fix: handle an empty staged diff in the commit TUI",
+			limit:   0,
+			want:    "fix: handle an empty staged diff in the commit TUI",
+		},
+		{
+			name:    "negative limit disables truncation",
+			message: "fix: handle an empty staged diff",
+			limit:   -1,
+			want:    "fix: handle an empty staged diff",
+		},
+		{
+			name:    "exactly at the limit",
+			message: "fix: handle empty diffs",
+			limit:   23,
+			want:    "fix: handle empty diffs",
+		},
+		{
+			name:    "cut at a word boundary",
+			message: "fix: handle empty diffs",
+			limit:   22,
+			want:    "fix: handle empty",
+		},
+		{
+			name:    "trailing punctuation dropped",
+			message: "fix: handle a, b and c",
+			limit:   15,
+			want:    "fix: handle a",
+		},
+		{
+			name:    "long word cut at the limit",
+			message: "fix: supercalifragilistic",
+			limit:   10,
+			want:    "fix: super",
+		},
+		{
+			name:    "body kept",
+			message: "feat(ui): show the diff summary above the message\n\nLists each file.",
+			limit:   30,
+			want:    "feat(ui): show the diff\n\nLists each file.",
+		},
+		{
+			name:    "japanese without spaces counts characters",
+			message: "feat: 日本語のコミットメッセージを追加する",
+			limit:   10,
+			want:    "feat: 日本語の",
+		},
+		{
+			name:    "japanese at the limit",
+			message: "feat: 日本語の",
+			limit:   10,
+			want:    "feat: 日本語の",
+		},
+		{
+			name:    "japanese with spaces",
+			message: "fix: 設定 を 読み込む",
+			limit:   10,
+			want:    "fix: 設定 を",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := TruncateSubject(tt.message, tt.limit)
+			if got != tt.want {
+				t.Errorf("TruncateSubject(%d) = %q, want %q", tt.limit, got, tt.want)
+			}
+			if tt.limit > 0 && SubjectLength(got) > tt.limit {
+				t.Errorf("subject has %d characters, limit %d", SubjectLength(got), tt.limit)
+			}
+		})
+	}
+}
+
+func TestNewFinalizer(t *testing.T) {
+	tests := []struct {
+		name     string
+		branch   string
+		pattern  string
+		signoff  bool
+		identity bool
+		want     Finalizer
+		wantErr  string
+	}{
+		{
+			name:   "no pattern",
+			branch: "feature/ABC-123-login",
+			want:   Finalizer{TicketPlacement: "footer"},
+		},
+		{
+			name:    "ticket from the branch",
+			branch:  "feature/ABC-123-login",
+			pattern: `[A-Z]+-[0-9]+`,
+			want:    Finalizer{Ticket: "ABC-123", TicketPlacement: "footer"},
+		},
+		{
+			name:    "capture group",
+			branch:  "fix/gh-42",
+			pattern: `gh-([0-9]+)`,
+			want:    Finalizer{Ticket: "42", TicketPlacement: "footer"},
+		},
+		{
+			name:    "no branch",
+			pattern: `[A-Z]+-[0-9]+`,
+			want:    Finalizer{TicketPlacement: "footer"},
+		},
+		{
+			name:    "invalid pattern",
+			branch:  "main",
+			pattern: `[`,
+			wantErr: "invalid ticket pattern",
+		},
+		{
+			name:     "sign-off from the git identity",
+			signoff:  true,
+			identity: true,
+			want:     Finalizer{TicketPlacement: "footer", Signoff: "Signed-off-by: Jane Doe <jane@example.com>"},
+		},
+		{
+			name:    "sign-off without an identity",
+			signoff: true,
+			wantErr: "failed to determine identity for sign-off",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initRepo(t)
+			if tt.identity {
+				runGit(t, "config", "user.name", "Jane Doe")
+				runGit(t, "config", "user.email", "jane@example.com")
+			}
+
+			got, err := NewFinalizer(tt.branch, tt.pattern, "footer", tt.signoff)
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("NewFinalizer() error = %v, want it to mention %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NewFinalizer() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("NewFinalizer() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package git
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+)
+
+var diffHeaderRegex = regexp.MustCompile(`^diff --git a/(.*) b/(.*)$`)
+
+// RedactDiff replaces the hunk contents of files matching any of patterns
+// with a "[redacted N lines]" marker. File headers are kept so the file still
+// shows up as changed. Patterns are path.Match globs matched against both the
+// full path and the base name, so "*.pem" matches "certs/server.pem".
+func RedactDiff(diff string, patterns []string) string {
+	if len(patterns) == 0 || diff == "" {
+		return diff
+	}
+
+	var out []string
+	redacting, inHunk := false, false
+	redacted := 0
+	flush := func() {
+		if redacted > 0 {
+			out = append(out, fmt.Sprintf("[redacted %d lines]", redacted))
+		}
+		redacted = 0
+	}
+
+	for _, line := range strings.Split(diff, "\n") {
+		if matches := diffHeaderRegex.FindStringSubmatch(line); matches != nil {
+			flush()
+			redacting = matchesAnyPath(matches[1], patterns) || matchesAnyPath(matches[2], patterns)
+			inHunk = false
+			out = append(out, line)
+			continue
+		}
+
+		if redacting {
+			// Keep the extended header (index, ---/+++ lines) and drop
+			// everything from the first hunk on
+			if strings.HasPrefix(line, "@@") {
+				inHunk = true
+				continue
+			}
+			if inHunk {
+				redacted++
+				continue
+			}
+		}
+		out = append(out, line)
+	}
+	flush()
+
+	return strings.Join(out, "\n")
+}
+
+func matchesAnyPath(name string, patterns []string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+		if ok, _ := path.Match(pattern, path.Base(name)); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,48 @@
+package git
+
+import (
+	"os/exec"
+	"slices"
+	"strings"
+)
+
+// containerDirs hold one component per subdirectory, so scopes are taken
+// from the level below them (e.g. internal/git gives "git"). Files directly
+// inside them use the container name.
+var containerDirs = []string{"internal", "pkg", "cmd", "src", "lib", "apps", "packages", "services", "modules"}
+
+// ignoredScopeDirs never make useful scopes.
+var ignoredScopeDirs = []string{"vendor", "node_modules", "third_party", "testdata"}
+
+// DetectScopes derives commit scopes from the repository layout: the
+// top-level directories holding tracked files, with container directories
+// such as internal/ and pkg/ replaced by their subdirectories. The result is
+// sorted and free of duplicates, and the same from any directory of the
+// repository.
+func DetectScopes() ([]string, error) {
+	// List the whole tree by root-relative path, not just the current
+	// directory
+	output, err := exec.Command("git", "ls-files", "--full-name", "-z", "--", ":/").Output()
+	if err != nil {
+		return nil, err
+	}
+
+	var scopes []string
+	for _, path := range strings.Split(string(output), "\x00") {
+		parts := strings.Split(path, "/")
+		if len(parts) < 2 {
+			continue
+		}
+		scope := parts[0]
+		if slices.Contains(containerDirs, scope) && len(parts) > 2 {
+			scope = parts[1]
+		}
+		if strings.HasPrefix(scope, ".") || strings.HasPrefix(scope, "_") || slices.Contains(ignoredScopeDirs, scope) {
+			continue
+		}
+		scopes = append(scopes, scope)
+	}
+
+	slices.Sort(scopes)
+	return slices.Compact(scopes), nil
+}
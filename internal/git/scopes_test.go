@@ -0,0 +1,60 @@
+package git
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestDetectScopes(t *testing.T) {
+	tests := []struct {
+		name  string
+		files []string
+		want  []string
+	}{
+		{
+			name:  "top-level directories",
+			files: []string{"api/server.go", "web/index.html", "main.go"},
+			want:  []string{"api", "web"},
+		},
+		{
+			name:  "container directories use their subdirectories",
+			files: []string{"internal/git/diff.go", "internal/ui/tui.go", "pkg/gelf/gelf.go", "cmd/root.go"},
+			want:  []string{"cmd", "gelf", "git", "ui"},
+		},
+		{
+			name:  "hidden and vendored directories are skipped",
+			files: []string{".github/workflows/ci.yml", "vendor/x/x.go", "_tools/gen.go", "docs/usage.md"},
+			want:  []string{"docs"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			root := initRepo(t)
+			for _, file := range tt.files {
+				writeFile(t, file, "x\n")
+			}
+			runGit(t, "add", "-A")
+			runGit(t, "commit", "-q", "-m", "add files")
+
+			got, err := DetectScopes()
+			if err != nil {
+				t.Fatalf("DetectScopes() error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("DetectScopes() = %q, want %q", got, tt.want)
+			}
+
+			// The result does not depend on the current directory
+			t.Chdir(filepath.Dir(filepath.Join(root, tt.files[0])))
+			got, err = DetectScopes()
+			if err != nil {
+				t.Fatalf("DetectScopes() from a subdirectory error = %v", err)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("DetectScopes() from a subdirectory = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
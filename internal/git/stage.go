@@ -0,0 +1,67 @@
+package git
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// StageAll stages every change in the working tree, including untracked and
+// deleted files (git add -A).
+func StageAll() error {
+	return runAdd("-A")
+}
+
+// StageFiles stages the given paths, which are relative to the repository
+// root as returned by UnstagedFiles.
+func StageFiles(files []string) error {
+	if len(files) == 0 {
+		return nil
+	}
+
+	args := []string{"-A", "--"}
+	for _, file := range files {
+		// git add resolves plain paths against the current directory
+		args = append(args, ":(top,literal)"+file)
+	}
+	return runAdd(args...)
+}
+
+func runAdd(args ...string) error {
+	output, err := exec.Command("git", append([]string{"add"}, args...)...).CombinedOutput()
+	if err != nil {
+		if detail := strings.TrimSpace(string(output)); detail != "" {
+			return fmt.Errorf("git add failed: %w\n%s", err, detail)
+		}
+		return fmt.Errorf("git add failed: %w", err)
+	}
+	return nil
+}
+
+// UnstagedFiles returns the paths with changes not yet staged, including
+// untracked files, as listed by git status --porcelain (relative to the
+// repository root).
+func UnstagedFiles() ([]string, error) {
+	output, err := exec.Command("git", "status", "--porcelain", "-z", "--untracked-files=all").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get status: %w", err)
+	}
+
+	var files []string
+	entries := strings.Split(string(output), "\x00")
+	for i := 0; i < len(entries); i++ {
+		entry := entries[i]
+		if len(entry) < 4 {
+			continue
+		}
+		x, y, path := entry[0], entry[1], entry[3:]
+		if x == 'R' || x == 'C' {
+			// Renames and copies are followed by the source path
+			i++
+		}
+		if y != ' ' || x == '?' {
+			files = append(files, path)
+		}
+	}
+	return files, nil
+}
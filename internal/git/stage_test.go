@@ -0,0 +1,93 @@
+package git
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// initRepo creates a repository with an initial commit in a temporary
+// directory, changes into it and returns its path.
+func initRepo(t *testing.T) string {
+	t.Helper()
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GIT_AUTHOR_NAME", "Test")
+	t.Setenv("GIT_AUTHOR_EMAIL", "test@example.com")
+	t.Setenv("GIT_COMMITTER_NAME", "Test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+
+	dir := t.TempDir()
+	t.Chdir(dir)
+	runGit(t, "init", "-q")
+	writeFile(t, "README.md", "hello\n")
+	runGit(t, "add", "README.md")
+	runGit(t, "commit", "-q", "-m", "init")
+	return dir
+}
+
+func runGit(t *testing.T, args ...string) string {
+	t.Helper()
+	output, err := exec.Command("git", args...).CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+	}
+	return string(output)
+}
+
+func writeFile(t *testing.T, name, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(name, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func stagedNames(t *testing.T) []string {
+	t.Helper()
+	return strings.Fields(runGit(t, "diff", "--staged", "--name-only"))
+}
+
+func TestStageFilesFromSubdirectory(t *testing.T) {
+	root := initRepo(t)
+	writeFile(t, "top.txt", "top\n")
+	writeFile(t, "sub/x/f.txt", "f\n")
+	writeFile(t, "sub/y.txt", "y\n")
+	t.Chdir(filepath.Join(root, "sub"))
+
+	files, err := UnstagedFiles()
+	if err != nil {
+		t.Fatalf("UnstagedFiles() error = %v", err)
+	}
+	want := []string{"sub/x/f.txt", "sub/y.txt", "top.txt"}
+	slices.Sort(files)
+	if !slices.Equal(files, want) {
+		t.Fatalf("UnstagedFiles() = %q, want %q", files, want)
+	}
+
+	if err := StageFiles([]string{"sub/x/f.txt", "top.txt"}); err != nil {
+		t.Fatalf("StageFiles() error = %v", err)
+	}
+	if got, want := stagedNames(t), []string{"sub/x/f.txt", "top.txt"}; !slices.Equal(got, want) {
+		t.Errorf("staged %q, want %q", got, want)
+	}
+}
+
+func TestStageAllFromSubdirectory(t *testing.T) {
+	root := initRepo(t)
+	writeFile(t, "top.txt", "top\n")
+	writeFile(t, "sub/f.txt", "f\n")
+	t.Chdir(filepath.Join(root, "sub"))
+
+	if err := StageAll(); err != nil {
+		t.Fatalf("StageAll() error = %v", err)
+	}
+	if got, want := stagedNames(t), []string{"sub/f.txt", "top.txt"}; !slices.Equal(got, want) {
+		t.Errorf("staged %q, want %q", got, want)
+	}
+}
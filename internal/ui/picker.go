@@ -0,0 +1,104 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// PickFiles shows files in a checklist on stderr and returns the ones the
+// user selected. Space toggles a file, "a" toggles all of them and enter
+// confirms; ok is false when the picker was cancelled.
+func PickFiles(title string, files []string) (selected []string, ok bool, err error) {
+	m := &pickerModel{title: title, files: files, checked: make([]bool, len(files))}
+	p := tea.NewProgram(m, tea.WithOutput(os.Stderr))
+	if _, err := p.Run(); err != nil {
+		return nil, false, err
+	}
+	if !m.confirmed {
+		return nil, false, nil
+	}
+
+	for i, file := range files {
+		if m.checked[i] {
+			selected = append(selected, file)
+		}
+	}
+	return selected, true, nil
+}
+
+type pickerModel struct {
+	title     string
+	files     []string
+	checked   []bool
+	cursor    int
+	confirmed bool
+	done      bool
+}
+
+func (m *pickerModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m *pickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "up", "k":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "down", "j":
+		if m.cursor < len(m.files)-1 {
+			m.cursor++
+		}
+	case " ", "x":
+		m.checked[m.cursor] = !m.checked[m.cursor]
+	case "a":
+		all := true
+		for _, checked := range m.checked {
+			all = all && checked
+		}
+		for i := range m.checked {
+			m.checked[i] = !all
+		}
+	case "enter":
+		m.confirmed = true
+		m.done = true
+		return m, tea.Quit
+	case "q", "esc", "ctrl+c", "ctrl+d":
+		m.done = true
+		return m, tea.Quit
+	}
+	return m, nil
+}
+
+func (m *pickerModel) View() string {
+	if m.done {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteString(titleStyle.Render(m.title))
+	b.WriteString("\n\n")
+	for i, file := range m.files {
+		cursor := "  "
+		if i == m.cursor {
+			cursor = "> "
+		}
+		check := "[ ]"
+		if m.checked[i] {
+			check = "[x]"
+		}
+		fmt.Fprintf(&b, "%s%s %s\n", cursor, check, fileStyle.Render(file))
+	}
+	b.WriteString("\n")
+	b.WriteString(promptStyle.Render("space: toggle • a: all • enter: stage selected • q: cancel"))
+	b.WriteString("\n")
+	return b.String()
+}
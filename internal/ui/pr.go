@@ -11,6 +11,7 @@ import (
 )
 
 type prModel struct {
+	ctx            context.Context
 	aiClient       *ai.VertexAIClient
 	input          ai.PullRequestInput
 	diffSummary    git.DiffSummary
@@ -23,11 +24,12 @@ type prModel struct {
 	confirmPrompt  string
 }
 
-func NewPRTUI(aiClient *ai.VertexAIClient, input ai.PullRequestInput, render bool, useColor bool, confirmPrompt string) *prModel {
+func NewPRTUI(ctx context.Context, aiClient *ai.VertexAIClient, input ai.PullRequestInput, render bool, useColor bool, confirmPrompt string) *prModel {
 	diffSummary := git.ParseDiffSummary(input.Diff)
 	commitLines := parseCommitLines(input.CommitLog)
 
 	return &prModel{
+		ctx:         ctx,
 		aiClient:    aiClient,
 		input:       input,
 		diffSummary: diffSummary,
@@ -44,10 +46,9 @@ func NewPRTUI(aiClient *ai.VertexAIClient, input ai.PullRequestInput, render boo
 }
 
 func (m *prModel) Run() (*ai.PullRequestContent, bool, error) {
-	ctx := context.Background()
 	loadingContext := formatPRContext(m.diffSummary, m.commitLines)
 	stopSpinner := m.startLoadingIndicator(loadingContext)
-	content, err := m.aiClient.GeneratePullRequestContent(ctx, m.input)
+	content, err := m.aiClient.GeneratePullRequestContent(m.ctx, m.input)
 	stopSpinner()
 	if err != nil {
 		return nil, false, err
@@ -103,39 +104,10 @@ func (m *prModel) buildPRContent() string {
 	return strings.Join(sections, "\n\n")
 }
 
-func formatPRDiffSummary(summary git.DiffSummary) string {
-	if len(summary.Files) == 0 {
-		return ""
-	}
-
-	var parts []string
-	parts = append(parts, diffStyle.Render("📄 Changed Files:"))
-
-	for _, file := range summary.Files {
-		fileName := fileStyle.Render(file.Name)
-
-		var changes []string
-		if file.AddedLines > 0 {
-			changes = append(changes, addedStyle.Render(fmt.Sprintf("+%d", file.AddedLines)))
-		}
-		if file.DeletedLines > 0 {
-			changes = append(changes, deletedStyle.Render(fmt.Sprintf("-%d", file.DeletedLines)))
-		}
-
-		if len(changes) > 0 {
-			parts = append(parts, fmt.Sprintf(" • %s (%s)", fileName, strings.Join(changes, ", ")))
-		} else {
-			parts = append(parts, fmt.Sprintf(" • %s", fileName))
-		}
-	}
-
-	return strings.Join(parts, "\n")
-}
-
 func formatPRContext(summary git.DiffSummary, commitLines []string) string {
 	sections := []string{}
 
-	diffSummary := formatPRDiffSummary(summary)
+	diffSummary := formatDiffSummary(summary)
 	if diffSummary != "" {
 		sections = append(sections, diffSummary)
 	}
@@ -51,6 +51,28 @@ func PromptYesNoWithWriter(prompt string, out io.Writer) (bool, error) {
 	return false, nil
 }
 
+// PromptString asks for a line of input on out, returning defaultValue when
+// the answer is empty. The reader is shared across prompts so buffered input
+// is not lost.
+func PromptString(reader *bufio.Reader, out io.Writer, prompt, defaultValue string) (string, error) {
+	if defaultValue != "" {
+		fmt.Fprintf(out, "%s [%s]: ", promptStyle.Render(prompt), defaultValue)
+	} else {
+		fmt.Fprintf(out, "%s: ", promptStyle.Render(prompt))
+	}
+
+	line, err := reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+
+	line = strings.TrimSpace(line)
+	if line == "" {
+		return defaultValue, nil
+	}
+	return line, nil
+}
+
 type yesNoModel struct {
 	prompt    string
 	confirmed bool
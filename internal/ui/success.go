@@ -9,3 +9,8 @@ func RenderSuccessHeader(text string) string {
 func RenderSuccessMessage(text string) string {
 	return messageStyle.Render(text)
 }
+
+// RenderWarning applies warning styling to a line.
+func RenderWarning(text string) string {
+	return warningStyle.Render(text)
+}
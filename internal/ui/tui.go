@@ -2,13 +2,15 @@ package ui
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/EkeMinusYou/gelf/internal/ai"
 	"github.com/EkeMinusYou/gelf/internal/git"
+	"github.com/charmbracelet/bubbles/key"
 	"github.com/charmbracelet/bubbles/spinner"
-	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/charmbracelet/bubbles/textarea"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 )
@@ -17,6 +19,7 @@ type state int
 
 const (
 	stateLoading state = iota
+	stateSelecting
 	stateConfirm
 	stateEditing
 	stateCommitting
@@ -25,47 +28,92 @@ const (
 )
 
 type model struct {
+	ctx             context.Context
+	cancel          context.CancelFunc
 	aiClient        *ai.VertexAIClient
-	diff            string
+	input           ai.CommitMessageInput
 	diffSummary     git.DiffSummary
 	commitMessage   string
 	originalMessage string
+	candidates      []string
+	streamed        string
+	stream          chan tea.Msg
+	cursor          int
+	regenerations   int
 	err             error
 	state           state
 	spinner         spinner.Model
-	textInput       textinput.Model
-	commitLanguage  string
+	textInput       textarea.Model
+	options         CommitOptions
+}
+
+// CommitOptions controls how the generated commit message is finalized
+// before it is shown for confirmation.
+type CommitOptions struct {
+	// Finalizer adds the ticket reference and sign-off to each message.
+	git.Finalizer
+	// Candidates is the number of alternative messages to request. When the
+	// model returns more than one, they are offered as a selectable list.
+	Candidates int
+	// Amend rewrites the last commit instead of creating a new one.
+	Amend bool
+	// PreviousMessage is the message of the commit being amended, shown
+	// next to the new one so the rewrite can be checked.
+	PreviousMessage string
+	// MaxSubjectLength is the subject limit; longer subjects are flagged
+	// before committing. Zero disables the check.
+	MaxSubjectLength int
+	// Lint checks the message against Conventional Commits and lists the
+	// rules it violates before committing.
+	Lint bool
+	// Types are the commit types Lint accepts (the defaults when empty).
+	Types []string
+	// NoVerify skips the pre-commit and commit-msg hooks.
+	NoVerify bool
 }
 
 type msgCommitGenerated struct {
-	message string
-	err     error
+	messages []string
+	err      error
+}
+
+// msgCommitChunk carries the text streamed so far for a single message.
+type msgCommitChunk struct {
+	text string
 }
 
 type msgCommitDone struct {
 	err error
 }
 
-func NewTUI(aiClient *ai.VertexAIClient, diff string, commitLanguage string) *model {
+// NewTUI creates the commit TUI. Cancelling ctx, or pressing ctrl+c while a
+// message is being generated or committed, aborts the in-flight AI request
+// or git commit.
+func NewTUI(ctx context.Context, aiClient *ai.VertexAIClient, input ai.CommitMessageInput, options CommitOptions) *model {
 	s := spinner.New()
 	s.Spinner = spinner.Dot
 	s.Style = loadingStyle
 
-	ti := textinput.New()
+	ti := textarea.New()
 	ti.Placeholder = "Enter your commit message..."
 	ti.CharLimit = 0
-	ti.Width = 60
+	ti.ShowLineNumbers = false
+	ti.KeyMap.InsertNewline = key.NewBinding(key.WithKeys("ctrl+j", "alt+enter"))
+	ti.SetWidth(72)
 
-	diffSummary := git.ParseDiffSummary(diff)
+	diffSummary := git.ParseDiffSummary(input.Diff)
+	ctx, cancel := context.WithCancel(ctx)
 
 	return &model{
-		aiClient:       aiClient,
-		diff:           diff,
-		diffSummary:    diffSummary,
-		state:          stateLoading,
-		spinner:        s,
-		textInput:      ti,
-		commitLanguage: commitLanguage,
+		ctx:         ctx,
+		cancel:      cancel,
+		aiClient:    aiClient,
+		input:       input,
+		diffSummary: diffSummary,
+		state:       stateLoading,
+		spinner:     s,
+		textInput:   ti,
+		options:     options,
 	}
 }
 
@@ -81,6 +129,25 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		switch m.state {
 		case stateLoading:
 			switch msg.String() {
+			case "q", "ctrl+c":
+				m.cancel()
+				return m, tea.Quit
+			}
+		case stateSelecting:
+			switch msg.String() {
+			case "up", "k":
+				if m.cursor > 0 {
+					m.cursor--
+				}
+			case "down", "j":
+				if m.cursor < len(m.candidates)-1 {
+					m.cursor++
+				}
+			case "enter":
+				m.commitMessage = m.options.Finalize(m.candidates[m.cursor])
+				m.state = stateConfirm
+			case "r", "R":
+				return m, m.regenerate()
 			case "q", "ctrl+c":
 				return m, tea.Quit
 			}
@@ -91,13 +158,21 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m, tea.Batch(m.spinner.Tick, m.commitChanges())
 			case "e", "E":
 				m.originalMessage = m.commitMessage
+				m.textInput.SetHeight(strings.Count(m.commitMessage, "\n") + 2)
 				m.textInput.SetValue(m.commitMessage)
 				m.textInput.Focus()
 				m.state = stateEditing
-				return m, textinput.Blink
+				return m, textarea.Blink
+			case "r", "R":
+				return m, m.regenerate()
 			case "n", "N", "q", "ctrl+c":
 				return m, tea.Quit
 			}
+		case stateCommitting:
+			if msg.String() == "ctrl+c" {
+				// Wait for msgCommitDone so git has exited before quitting
+				m.cancel()
+			}
 		case stateEditing:
 			switch msg.String() {
 			case "enter":
@@ -113,19 +188,31 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				m.state = stateConfirm
 			default:
 				m.textInput, cmd = m.textInput.Update(msg)
+				m.textInput.SetHeight(m.textInput.LineCount() + 1)
 				return m, cmd
 			}
 		case stateSuccess, stateError:
 			return m, tea.Quit
 		}
 
+	case msgCommitChunk:
+		m.streamed = msg.text
+		return m, waitForStream(m.stream)
+
 	case msgCommitGenerated:
+		m.streamed = ""
 		if msg.err != nil {
 			m.err = msg.err
 			m.state = stateError
 		} else {
-			m.commitMessage = msg.message
-			m.state = stateConfirm
+			m.candidates = msg.messages
+			m.cursor = 0
+			if len(m.candidates) > 1 {
+				m.state = stateSelecting
+			} else {
+				m.commitMessage = m.options.Finalize(m.candidates[0])
+				m.state = stateConfirm
+			}
 		}
 
 	case msgCommitDone:
@@ -150,9 +237,16 @@ func (m *model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 func (m *model) View() string {
 	switch m.state {
 	case stateLoading:
+		status := "Generating commit message..."
+		if m.regenerations > 0 {
+			status = fmt.Sprintf("Regenerating commit message (attempt %d)...", m.regenerations+1)
+		}
 		loadingText := fmt.Sprintf("%s %s",
 			m.spinner.View(),
-			loadingStyle.Render("Generating commit message..."))
+			loadingStyle.Render(status))
+		if m.streamed != "" {
+			loadingText += "\n\n" + messageStyle.Render(strings.TrimSpace(m.streamed))
+		}
 
 		diffSummary := m.formatDiffSummary()
 		if diffSummary != "" {
@@ -160,22 +254,60 @@ func (m *model) View() string {
 		}
 		return loadingText
 
+	case stateSelecting:
+		diffSummary := m.formatDiffSummary()
+		header := titleStyle.Render("📝 Choose a Commit Message:")
+
+		var items []string
+		for i, candidate := range m.candidates {
+			subject, _, _ := strings.Cut(candidate, "\n")
+			if i == m.cursor {
+				items = append(items, messageStyle.Render("❯ "+subject))
+			} else {
+				items = append(items, "  "+subject)
+			}
+		}
+		prompt := promptStyle.Render("↑/↓ to move, Enter to select, (r)egenerate, (q) to quit")
+
+		sections := []string{}
+		if diffSummary != "" {
+			sections = append(sections, diffSummary)
+		}
+		sections = append(sections, header, strings.Join(items, "\n"), prompt)
+		return strings.Join(sections, "\n\n")
+
 	case stateConfirm:
 		diffSummary := m.formatDiffSummary()
 		header := titleStyle.Render("📝 Generated Commit Message:")
 		message := messageStyle.Render(m.commitMessage)
-		prompt := promptStyle.Render("Commit this message? (y)es / (e)dit / (n)o")
+		question := "Commit this message?"
+		if m.options.Amend {
+			question = "Amend the last commit with this message?"
+		}
+		prompt := promptStyle.Render(question + " (y)es / (e)dit / (r)egenerate / (n)o")
 
+		sections := []string{}
 		if diffSummary != "" {
-			return fmt.Sprintf("%s\n\n%s\n\n%s\n\n%s", diffSummary, header, message, prompt)
+			sections = append(sections, diffSummary)
+		}
+		if m.options.Ticket != "" {
+			sections = append(sections, diffStyle.Render("🎫 Detected ticket: ")+fileStyle.Render(m.options.Ticket))
+		}
+		if m.options.Amend && m.options.PreviousMessage != "" {
+			sections = append(sections, m.formatSubjectChange())
 		}
-		return fmt.Sprintf("%s\n\n%s\n\n%s", header, message, prompt)
+		sections = append(sections, header, message)
+		if warnings := m.messageWarnings(); len(warnings) > 0 {
+			sections = append(sections, warningStyle.Render(strings.Join(warnings, "\n")))
+		}
+		sections = append(sections, prompt)
+		return strings.Join(sections, "\n\n")
 
 	case stateEditing:
 		diffSummary := m.formatDiffSummary()
 		header := titleStyle.Render("✏️  Edit Commit Message:")
 		inputView := m.textInput.View()
-		prompt := editPromptStyle.Render("Press Enter to confirm, Esc to cancel")
+		prompt := editPromptStyle.Render("Press Enter to confirm, Ctrl+J for a new line, Esc to cancel")
 
 		if diffSummary != "" {
 			return fmt.Sprintf("%s\n\n%s\n\n%s\n\n%s", diffSummary, header, inputView, prompt)
@@ -185,7 +317,7 @@ func (m *model) View() string {
 	case stateCommitting:
 		return fmt.Sprintf("%s %s",
 			m.spinner.View(),
-			loadingStyle.Render("Committing changes..."))
+			loadingStyle.Render(m.committingText()))
 
 	case stateSuccess:
 		return ""
@@ -198,35 +330,141 @@ func (m *model) View() string {
 }
 
 func (m *model) generateCommitMessage() tea.Cmd {
-	return tea.Cmd(func() tea.Msg {
-		ctx := context.Background()
-		message, err := m.aiClient.GenerateCommitMessage(ctx, m.diff, m.commitLanguage)
-		return msgCommitGenerated{
-			message: strings.TrimSpace(message),
-			err:     err,
+	if m.options.Candidates > 1 {
+		return tea.Cmd(func() tea.Msg {
+			messages, err := m.aiClient.GenerateCommitMessageCandidates(m.ctx, m.input, m.options.Candidates)
+			return msgCommitGenerated{
+				messages: messages,
+				err:      err,
+			}
+		})
+	}
+
+	// A single message is streamed so it appears as it is generated
+	ctx, aiClient, input := m.ctx, m.aiClient, m.input
+	stream := make(chan tea.Msg)
+	m.stream = stream
+	m.streamed = ""
+
+	go func() {
+		defer close(stream)
+		send := func(msg tea.Msg) {
+			select {
+			case stream <- msg:
+			case <-ctx.Done():
+			}
 		}
-	})
+
+		message, err := aiClient.GenerateCommitMessageStream(ctx, input, func(text string) {
+			send(msgCommitChunk{text: text})
+		})
+		if err != nil {
+			send(msgCommitGenerated{err: err})
+			return
+		}
+		send(msgCommitGenerated{messages: []string{message}})
+	}()
+
+	return waitForStream(stream)
+}
+
+// waitForStream waits for the next message from a streaming generation.
+func waitForStream(stream <-chan tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		msg, ok := <-stream
+		if !ok {
+			return nil
+		}
+		return msg
+	}
+}
+
+// regenerate discards the current suggestions and asks the model again,
+// passing the rejected messages so it can phrase the next one differently.
+func (m *model) regenerate() tea.Cmd {
+	m.input.Rejected = append(m.input.Rejected, m.candidates...)
+	m.regenerations++
+	m.candidates = nil
+	m.cursor = 0
+	m.commitMessage = ""
+	m.state = stateLoading
+	return tea.Batch(m.spinner.Tick, m.generateCommitMessage())
 }
 
 func (m *model) commitChanges() tea.Cmd {
 	return tea.Cmd(func() tea.Msg {
-		err := git.CommitChanges(m.commitMessage)
+		var err error
+		if m.options.Amend {
+			err = git.AmendCommit(m.ctx, m.commitMessage, m.options.NoVerify)
+		} else {
+			err = git.CommitChanges(m.ctx, m.commitMessage, m.options.NoVerify)
+		}
+		if errors.Is(err, context.Canceled) {
+			err = fmt.Errorf("commit cancelled")
+		} else if err != nil {
+			err = fmt.Errorf("git commit failed: %w", err)
+		}
 		return msgCommitDone{err: err}
 	})
 }
 
+// formatSubjectChange shows the subject of the commit being amended next to
+// the new subject.
+func (m *model) formatSubjectChange() string {
+	oldSubject, _, _ := strings.Cut(m.options.PreviousMessage, "\n")
+	newSubject, _, _ := strings.Cut(m.commitMessage, "\n")
+
+	header := diffStyle.Render("🔁 Subject change:")
+	if oldSubject == newSubject {
+		return header + "\n" + diffStyle.Render("  "+oldSubject+" (unchanged)")
+	}
+	return header + "\n" +
+		deletedStyle.Render("- "+oldSubject) + "\n" +
+		addedStyle.Render("+ "+newSubject)
+}
+
+func (m *model) committingText() string {
+	if m.options.Amend {
+		return "Amending commit..."
+	}
+	return "Committing changes..."
+}
+
 func (m *model) formatDiffSummary() string {
-	if len(m.diffSummary.Files) == 0 {
+	return formatDiffSummary(m.diffSummary)
+}
+
+// maxSummaryFiles limits the number of files listed in diff summaries.
+// Zero lists every file.
+var maxSummaryFiles = 20
+
+// SetMaxSummaryFiles sets the number of files listed in diff summaries before
+// the rest are collapsed into a "… and N more files" line. Zero lists every
+// file.
+func SetMaxSummaryFiles(n int) {
+	maxSummaryFiles = n
+}
+
+func formatDiffSummary(summary git.DiffSummary) string {
+	if len(summary.Files) == 0 {
 		return ""
 	}
 
 	var parts []string
 	parts = append(parts, diffStyle.Render("📄 Changed Files:"))
 
-	for _, file := range m.diffSummary.Files {
-		fileName := fileStyle.Render(file.Name)
+	files := summary.Files
+	if maxSummaryFiles > 0 && len(files) > maxSummaryFiles {
+		files = files[:maxSummaryFiles]
+	}
+
+	for _, file := range files {
+		fileName := fileStyle.Render(file.DisplayName())
 
 		var changes []string
+		if file.Binary {
+			changes = append(changes, diffStyle.Render("binary"))
+		}
 		if file.AddedLines > 0 {
 			changes = append(changes, addedStyle.Render(fmt.Sprintf("+%d", file.AddedLines)))
 		}
@@ -241,16 +479,37 @@ func (m *model) formatDiffSummary() string {
 		}
 	}
 
+	if hidden := len(summary.Files) - len(files); hidden > 0 {
+		more := fmt.Sprintf(" … and %d more files", hidden)
+		if hidden == 1 {
+			more = " … and 1 more file"
+		}
+		parts = append(parts, diffStyle.Render(more))
+	}
+
 	return strings.Join(parts, "\n")
 }
 
+// CommittedMessage returns the message that was committed, and false when
+// the TUI exited without committing.
+func (m *model) CommittedMessage() (string, bool) {
+	if m.state != stateSuccess {
+		return "", false
+	}
+	return m.commitMessage, true
+}
+
 func (m *model) Run() error {
 	p := tea.NewProgram(m)
 	_, err := p.Run()
+	m.cancel()
 
 	// Print success message after TUI exits so it remains visible
 	if m.state == stateSuccess {
 		header := successStyle.Render("✓ Commit successful")
+		if m.options.Amend {
+			header = successStyle.Render("✓ Commit amended")
+		}
 		message := messageStyle.Render(m.commitMessage)
 
 		fmt.Printf("%s\n%s\n", header, message)
@@ -302,19 +561,42 @@ var (
 
 	deletedStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("1"))
+
+	warningStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("3")).
+			Bold(true)
 )
 
+// messageWarnings lists the problems with the message awaiting confirmation,
+// so they can be fixed with (e)dit or (r)egenerate.
+func (m *model) messageWarnings() []string {
+	var warnings []string
+	if length := git.SubjectLength(m.commitMessage); m.options.MaxSubjectLength > 0 && length > m.options.MaxSubjectLength {
+		warnings = append(warnings, fmt.Sprintf("⚠ Subject is %d characters (limit %d)", length, m.options.MaxSubjectLength))
+	}
+	if m.options.Lint {
+		if err := git.ValidateConventionalCommit(m.commitMessage, m.options.Types); err != nil {
+			for _, problem := range strings.Split(err.Error(), "\n") {
+				warnings = append(warnings, "⚠ "+problem)
+			}
+		}
+	}
+	return warnings
+}
+
+// DisableColor replaces every style with a plain one, so rendered text
+// contains no ANSI escape sequences (bold and italic included).
 func DisableColor() {
-	// No color styles
-	titleStyle = lipgloss.NewStyle().Bold(true)
-	messageStyle = lipgloss.NewStyle().Bold(true).Italic(true)
-	promptStyle = lipgloss.NewStyle().Bold(true)
-	successStyle = lipgloss.NewStyle().Bold(true)
-	errorStyle = lipgloss.NewStyle().Bold(true)
-	loadingStyle = lipgloss.NewStyle().Bold(true)
-	editPromptStyle = lipgloss.NewStyle().Bold(true)
+	titleStyle = lipgloss.NewStyle()
+	messageStyle = lipgloss.NewStyle()
+	promptStyle = lipgloss.NewStyle()
+	successStyle = lipgloss.NewStyle()
+	errorStyle = lipgloss.NewStyle()
+	loadingStyle = lipgloss.NewStyle()
+	editPromptStyle = lipgloss.NewStyle()
 	diffStyle = lipgloss.NewStyle()
-	fileStyle = lipgloss.NewStyle().Bold(true)
+	fileStyle = lipgloss.NewStyle()
 	addedStyle = lipgloss.NewStyle()
 	deletedStyle = lipgloss.NewStyle()
+	warningStyle = lipgloss.NewStyle()
 }
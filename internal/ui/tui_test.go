@@ -0,0 +1,209 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/EkeMinusYou/gelf/internal/git"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/muesli/termenv"
+)
+
+func TestMain(m *testing.M) {
+	// Render plain text regardless of the terminal running the tests
+	lipgloss.SetColorProfile(termenv.Ascii)
+	os.Exit(m.Run())
+}
+
+func TestFormatSubjectChange(t *testing.T) {
+	tests := []struct {
+		name     string
+		previous string
+		message  string
+		want     []string
+	}{
+		{
+			name:     "changed subject",
+			previous: "fix: handle empty diff\n\nOld body",
+			message:  "fix(git): handle an empty staged diff\n\nNew body",
+			want: []string{
+				"🔁 Subject change:",
+				"- fix: handle empty diff",
+				"+ fix(git): handle an empty staged diff",
+			},
+		},
+		{
+			name:     "unchanged subject",
+			previous: "feat: add --amend\n\nOld body",
+			message:  "feat: add --amend\n\nNew body",
+			want: []string{
+				"🔁 Subject change:",
+				"  feat: add --amend (unchanged)",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := &model{
+				options:       CommitOptions{Amend: true, PreviousMessage: tt.previous},
+				commitMessage: tt.message,
+			}
+			if got, want := m.formatSubjectChange(), strings.Join(tt.want, "\n"); got != want {
+				t.Errorf("formatSubjectChange()\n got %q\nwant %q", got, want)
+			}
+		})
+	}
+}
+
+func summaryWithFiles(n int) git.DiffSummary {
+	summary := git.DiffSummary{}
+	for i := range n {
+		summary.Files = append(summary.Files, git.FileDiff{Name: fmt.Sprintf("file%d.go", i), AddedLines: 1})
+	}
+	return summary
+}
+
+func TestFormatDiffSummaryLimit(t *testing.T) {
+	tests := []struct {
+		name      string
+		limit     int
+		files     int
+		wantFiles int
+		wantMore  string
+	}{
+		{name: "several hidden files", limit: 3, files: 6, wantFiles: 3, wantMore: " … and 3 more files"},
+		{name: "one hidden file", limit: 3, files: 4, wantFiles: 3, wantMore: " … and 1 more file"},
+		{name: "within the limit", limit: 3, files: 3, wantFiles: 3},
+		{name: "zero means unlimited", limit: 0, files: 30, wantFiles: 30},
+	}
+
+	defer SetMaxSummaryFiles(maxSummaryFiles)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetMaxSummaryFiles(tt.limit)
+			lines := strings.Split(formatDiffSummary(summaryWithFiles(tt.files)), "\n")
+
+			listed := 0
+			for _, line := range lines {
+				if strings.HasPrefix(line, " • ") {
+					listed++
+				}
+			}
+			if listed != tt.wantFiles {
+				t.Errorf("listed %d files, want %d", listed, tt.wantFiles)
+			}
+
+			last := lines[len(lines)-1]
+			if tt.wantMore == "" {
+				if strings.Contains(last, "more file") {
+					t.Errorf("unexpected overflow line %q", last)
+				}
+			} else if last != tt.wantMore {
+				t.Errorf("last line = %q, want %q", last, tt.wantMore)
+			}
+		})
+	}
+}
+
+func TestCommitOptionsFinalize(t *testing.T) {
+	const signoff = "Signed-off-by: Jane Doe <jane@example.com>"
+	breaking := "feat(api)!: drop the v1 endpoints\n\nRemove the handlers.\n\nBREAKING CHANGE: call /v2 instead"
+
+	tests := []struct {
+		name    string
+		options CommitOptions
+		message string
+		want    string
+	}{
+		{
+			name:    "footer ticket and sign-off after a BREAKING CHANGE footer",
+			options: CommitOptions{Finalizer: git.Finalizer{Ticket: "ABC-123", TicketPlacement: "footer", Signoff: signoff}},
+			message: breaking,
+			want: "feat(api)!: drop the v1 endpoints\n\nRemove the handlers.\n\n" +
+				"BREAKING CHANGE: call /v2 instead\nRefs: ABC-123\n" + signoff,
+		},
+		{
+			name:    "scope ticket with a BREAKING CHANGE footer and sign-off",
+			options: CommitOptions{Finalizer: git.Finalizer{Ticket: "ABC-123", TicketPlacement: "scope", Signoff: signoff}},
+			message: "feat!: drop the v1 endpoints\n\nBREAKING CHANGE: call /v2 instead",
+			want:    "feat(ABC-123)!: drop the v1 endpoints\n\nBREAKING CHANGE: call /v2 instead\n" + signoff,
+		},
+		{
+			name:    "scope placement falls back to a footer when a scope exists",
+			options: CommitOptions{Finalizer: git.Finalizer{Ticket: "ABC-123", TicketPlacement: "scope", Signoff: signoff}},
+			message: "fix(ui): align spinner",
+			want:    "fix(ui): align spinner\n\nRefs: ABC-123\n" + signoff,
+		},
+		{
+			name:    "subject only with sign-off",
+			options: CommitOptions{Finalizer: git.Finalizer{Signoff: signoff}},
+			message: "fix: typo",
+			want:    "fix: typo\n\n" + signoff,
+		},
+		{
+			name:    "nothing to add",
+			message: breaking,
+			want:    breaking,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.options.Finalize(tt.message); got != tt.want {
+				t.Errorf("Finalize()\n got %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestDisableColor(t *testing.T) {
+	styles := []*lipgloss.Style{
+		&titleStyle, &messageStyle, &promptStyle, &successStyle, &errorStyle, &loadingStyle,
+		&editPromptStyle, &diffStyle, &fileStyle, &addedStyle, &deletedStyle, &warningStyle,
+	}
+	saved := make([]lipgloss.Style, len(styles))
+	for i, style := range styles {
+		saved[i] = *style
+	}
+	t.Cleanup(func() {
+		for i, style := range styles {
+			*style = saved[i]
+		}
+		lipgloss.SetColorProfile(termenv.Ascii)
+	})
+
+	lipgloss.SetColorProfile(termenv.ANSI256)
+	if got := titleStyle.Render("title"); !strings.Contains(got, "\x1b[") {
+		t.Fatalf("styles render without escape sequences before DisableColor: %q", got)
+	}
+
+	DisableColor()
+
+	for i, style := range styles {
+		if got := style.Render("text"); strings.Contains(got, "\x1b[") {
+			t.Errorf("style %d renders escape sequences: %q", i, got)
+		}
+	}
+	summary := summaryWithFiles(2)
+	summary.Files = append(summary.Files, git.FileDiff{Name: "gone.go", DeletedLines: 3})
+	if got := formatDiffSummary(summary); strings.Contains(got, "\x1b[") {
+		t.Errorf("formatDiffSummary() renders escape sequences: %q", got)
+	}
+}
+
+func TestFormatDiffSummaryRenames(t *testing.T) {
+	summary := git.DiffSummary{Files: []git.FileDiff{
+		{Name: "new.go", OldName: "old.go", Renamed: true, AddedLines: 1},
+		{Name: "b.go", OldName: "a.go", Copied: true},
+	}}
+
+	got := formatDiffSummary(summary)
+	for _, file := range summary.Files {
+		if !strings.Contains(got, " • "+file.DisplayName()) {
+			t.Errorf("formatDiffSummary() does not list %q:\n%s", file.DisplayName(), got)
+		}
+	}
+}
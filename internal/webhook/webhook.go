@@ -0,0 +1,64 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// DefaultTimeout bounds each webhook request so a slow endpoint never holds
+// up the command.
+const DefaultTimeout = 5 * time.Second
+
+// CommitPayload is the JSON body posted to hooks.on_commit after a successful
+// commit.
+type CommitPayload struct {
+	Event   string `json:"event"`
+	Repo    string `json:"repo"`
+	Branch  string `json:"branch"`
+	Message string `json:"message"`
+	Amend   bool   `json:"amend"`
+}
+
+// NewCommitPayload builds the payload for a commit event.
+func NewCommitPayload(repo, branch, message string, amend bool) CommitPayload {
+	return CommitPayload{
+		Event:   "commit",
+		Repo:    repo,
+		Branch:  branch,
+		Message: message,
+		Amend:   amend,
+	}
+}
+
+// Post sends payload as JSON to url. It gives up after timeout and reports
+// non-2xx responses as errors; callers treat failures as best-effort.
+func Post(ctx context.Context, url string, payload any, timeout time.Duration) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to create webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhook request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
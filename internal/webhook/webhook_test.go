@@ -0,0 +1,83 @@
+package webhook
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestPostSuccess(t *testing.T) {
+	var got map[string]any
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("method = %s, want POST", r.Method)
+		}
+		if ct := r.Header.Get("Content-Type"); ct != "application/json" {
+			t.Errorf("Content-Type = %q, want application/json", ct)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&got); err != nil {
+			t.Errorf("failed to decode body: %v", err)
+		}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	payload := NewCommitPayload("gelf", "main", "feat: add webhook", true)
+	if err := Post(context.Background(), server.URL, payload, time.Second); err != nil {
+		t.Fatalf("Post() error = %v", err)
+	}
+
+	want := map[string]any{
+		"event":   "commit",
+		"repo":    "gelf",
+		"branch":  "main",
+		"message": "feat: add webhook",
+		"amend":   true,
+	}
+	if len(got) != len(want) {
+		t.Errorf("payload = %v, want %v", got, want)
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("payload[%q] = %v, want %v", key, got[key], value)
+		}
+	}
+}
+
+func TestPostErrorStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "boom", http.StatusInternalServerError)
+	}))
+	defer server.Close()
+
+	err := Post(context.Background(), server.URL, NewCommitPayload("gelf", "main", "fix: x", false), time.Second)
+	if err == nil || !strings.Contains(err.Error(), "500") {
+		t.Fatalf("Post() error = %v, want a 500 status error", err)
+	}
+}
+
+func TestPostTimeout(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-release:
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	start := time.Now()
+	err := Post(context.Background(), server.URL, NewCommitPayload("gelf", "main", "fix: x", false), 50*time.Millisecond)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("Post() error = %v, want a deadline exceeded error", err)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("Post() took %s, want it to give up after the timeout", elapsed)
+	}
+}
@@ -0,0 +1,210 @@
+// Package gelf generates commit messages and pull request titles and bodies
+// with Gemini, using the same configuration (gelf.yml and environment
+// variables) as the gelf CLI. It lets editor plugins and bots embed gelf
+// without shelling out.
+package gelf
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/EkeMinusYou/gelf/internal/ai"
+	"github.com/EkeMinusYou/gelf/internal/config"
+	"github.com/EkeMinusYou/gelf/internal/git"
+)
+
+// Client generates content with the configured backend. Create one with New
+// and release it with Close. A Client is safe for concurrent use.
+type Client struct {
+	cfg      *config.Config
+	commitAI *ai.VertexAIClient
+	prAI     *ai.VertexAIClient
+}
+
+// Options overrides configuration values for a Client. Zero values keep the
+// configured setting.
+type Options struct {
+	// CommitModel is the model used for commit messages ("flash", "pro" or
+	// a model name).
+	CommitModel string
+	// PRModel is the model used for pull requests ("flash", "pro" or a
+	// model name).
+	PRModel string
+	// NoPrelude leaves out the repository prompt prelude (.gelf/prompt.md).
+	NoPrelude bool
+}
+
+// New creates a Client from the gelf configuration.
+func New(ctx context.Context, opts Options) (*Client, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load configuration: %w", err)
+	}
+	if opts.CommitModel != "" {
+		cfg.FlashModel = cfg.ResolveModel(opts.CommitModel)
+	}
+	if opts.PRModel != "" {
+		cfg.PRModel = opts.PRModel
+	}
+
+	commitAI, err := ai.NewVertexAIClient(ctx, cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AI client: %w", err)
+	}
+
+	// Pull requests use their own model, so they need a separate client
+	prCfg := *cfg
+	prCfg.FlashModel = prCfg.ResolveModel(prCfg.PRModel)
+	prAI, err := ai.NewVertexAIClient(ctx, &prCfg)
+	if err != nil {
+		commitAI.Close()
+		return nil, fmt.Errorf("failed to create AI client: %w", err)
+	}
+
+	c := &Client{cfg: cfg, commitAI: commitAI, prAI: prAI}
+	if !opts.NoPrelude {
+		for _, client := range []*ai.VertexAIClient{commitAI, prAI} {
+			if err := client.UseRepoPromptPrelude(); err != nil {
+				c.Close()
+				return nil, err
+			}
+		}
+	}
+	return c, nil
+}
+
+// Close releases the clients' resources.
+func (c *Client) Close() error {
+	return errors.Join(c.commitAI.Close(), c.prAI.Close())
+}
+
+// CommitOptions customizes a generated commit message. Zero values use the
+// configured defaults.
+type CommitOptions struct {
+	// Language of the message (e.g. "english", "japanese").
+	Language string
+	// Branch is the current branch name, given to the model as context and
+	// searched for a ticket reference with commit.ticket_pattern.
+	Branch string
+	// Scope forces this Conventional Commits scope.
+	Scope string
+	// Concise asks for the shortest valid message.
+	Concise bool
+	// Gitmoji prefixes the subject with the gitmoji matching its type.
+	Gitmoji bool
+	// MaxSubjectLength is the longest subject line allowed.
+	MaxSubjectLength int
+	// Signoff adds a Signed-off-by trailer using git user.name and
+	// user.email, as does commit.signoff.
+	Signoff bool
+}
+
+// GenerateCommitMessage returns a Conventional Commits message for diff,
+// with the ticket reference and sign-off applied the way gelf commit does.
+func (c *Client) GenerateCommitMessage(ctx context.Context, diff string, opts CommitOptions) (string, error) {
+	if diff == "" {
+		return "", fmt.Errorf("diff is empty")
+	}
+
+	// Resolve everything that can fail before spending a generation
+	finalizer, err := c.finalizer(opts)
+	if err != nil {
+		return "", err
+	}
+
+	message, err := c.commitAI.GenerateCommitMessage(ctx, c.commitInput(diff, opts))
+	if err != nil {
+		return "", err
+	}
+	return finalizer.Finalize(message), nil
+}
+
+// commitInput applies opts over the configured defaults.
+func (c *Client) commitInput(diff string, opts CommitOptions) ai.CommitMessageInput {
+	input := ai.CommitMessageInput{
+		Diff:             diff,
+		Language:         c.cfg.CommitLanguage,
+		Branch:           opts.Branch,
+		Concise:          opts.Concise,
+		Gitmoji:          opts.Gitmoji || c.cfg.CommitGitmoji,
+		Scope:            opts.Scope,
+		Scopes:           c.cfg.CommitScopes,
+		MaxSubjectLength: c.cfg.CommitMaxSubjectLength,
+		Types:            c.cfg.CommitTypes,
+	}
+	if opts.Language != "" {
+		input.Language = opts.Language
+	}
+	if opts.MaxSubjectLength > 0 {
+		input.MaxSubjectLength = opts.MaxSubjectLength
+	}
+	return input
+}
+
+// finalizer returns the ticket reference and sign-off gelf commit would add
+// for opts.
+func (c *Client) finalizer(opts CommitOptions) (git.Finalizer, error) {
+	return git.NewFinalizer(opts.Branch, c.cfg.CommitTicketPattern, c.cfg.CommitTicketPlacement, opts.Signoff || c.cfg.CommitSignoff)
+}
+
+// StagedDiff returns the staged changes of the repository in the current
+// directory, honoring diff.context_lines and diff.exclude.
+func (c *Client) StagedDiff() (string, error) {
+	if err := git.EnsureRepo(); err != nil {
+		return "", err
+	}
+	return git.GetStagedDiff(git.DiffOptions{
+		ContextLines: c.cfg.DiffContextLines,
+		Exclude:      c.cfg.DiffExclude,
+	})
+}
+
+// PullRequestInput describes the changes of a pull request.
+type PullRequestInput struct {
+	BaseBranch string
+	HeadBranch string
+	// CommitLog lists the commits between the branches, one per line.
+	CommitLog string
+	// DiffStat is the output of git diff --stat between the branches.
+	DiffStat string
+	Diff     string
+	// Template is the repository's pull request template, if any.
+	Template string
+	// Language of the title and body; empty uses the configured language.
+	Language string
+}
+
+// PullRequest is a generated pull request title and body.
+type PullRequest struct {
+	Title string
+	Body  string
+}
+
+// GeneratePullRequest returns a title and body for the pull request described
+// by input.
+func (c *Client) GeneratePullRequest(ctx context.Context, input PullRequestInput) (*PullRequest, error) {
+	prInput := ai.PullRequestInput{
+		BaseBranch:    input.BaseBranch,
+		HeadBranch:    input.HeadBranch,
+		CommitLog:     input.CommitLog,
+		DiffStat:      input.DiffStat,
+		Diff:          input.Diff,
+		Template:      input.Template,
+		Language:      c.cfg.PRLanguage,
+		TitleLanguage: c.cfg.PRTitleLanguage,
+		BodyLanguage:  c.cfg.PRBodyLanguage,
+	}
+	if input.Language != "" {
+		// An explicit language applies to both the title and the body
+		prInput.Language = input.Language
+		prInput.TitleLanguage = input.Language
+		prInput.BodyLanguage = input.Language
+	}
+
+	content, err := c.prAI.GeneratePullRequestContent(ctx, prInput)
+	if err != nil {
+		return nil, err
+	}
+	return &PullRequest{Title: content.Title, Body: content.Body}, nil
+}
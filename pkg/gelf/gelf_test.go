@@ -0,0 +1,185 @@
+package gelf
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/EkeMinusYou/gelf/internal/config"
+)
+
+// useRepo creates a repository with content as its gelf.yml, changes into it
+// and isolates the test from the user's config files and environment. The
+// Gemini API backend is used so no credentials are needed.
+func useRepo(t *testing.T, content string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(home, ".config"))
+	t.Setenv("GIT_CONFIG_NOSYSTEM", "1")
+	t.Setenv("GELF_API_KEY", "test-key")
+	for _, name := range []string{"VERTEXAI_PROJECT", "GOOGLE_CLOUD_PROJECT", "VERTEXAI_LOCATION", "GEMINI_API_KEY"} {
+		t.Setenv(name, "")
+	}
+
+	t.Chdir(t.TempDir())
+	for _, args := range [][]string{
+		{"init", "-q"},
+		{"config", "user.name", "Jane Doe"},
+		{"config", "user.email", "jane@example.com"},
+	} {
+		if output, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %s: %v\n%s", strings.Join(args, " "), err, output)
+		}
+	}
+	if err := os.WriteFile("gelf.yml", []byte("backend: gemini\n"+content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func newClient(t *testing.T, opts Options) *Client {
+	t.Helper()
+	c, err := New(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestNewModels(t *testing.T) {
+	const content = "model:\n  flash: flash-model\n  pro: pro-model\npr:\n  model: pro\n"
+
+	tests := []struct {
+		name       string
+		opts       Options
+		wantCommit string
+		wantPR     string
+	}{
+		{name: "configured models", wantCommit: "flash-model", wantPR: "pro"},
+		{name: "commit model alias", opts: Options{CommitModel: "pro"}, wantCommit: "pro-model", wantPR: "pro"},
+		{name: "model names", opts: Options{CommitModel: "custom-model", PRModel: "flash"}, wantCommit: "custom-model", wantPR: "flash"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useRepo(t, content)
+			c := newClient(t, tt.opts)
+			if c.cfg.FlashModel != tt.wantCommit {
+				t.Errorf("commit model = %q, want %q", c.cfg.FlashModel, tt.wantCommit)
+			}
+			if c.cfg.PRModel != tt.wantPR {
+				t.Errorf("pull request model = %q, want %q", c.cfg.PRModel, tt.wantPR)
+			}
+		})
+	}
+}
+
+func TestNewInvalidConfig(t *testing.T) {
+	useRepo(t, "commit:\n  ticket_placement: header\n")
+	if _, err := New(context.Background(), Options{}); err == nil || !strings.Contains(err.Error(), "commit.ticket_placement") {
+		t.Fatalf("New() error = %v, want a commit.ticket_placement error", err)
+	}
+}
+
+func TestNewPromptPrelude(t *testing.T) {
+	useRepo(t, "")
+	if err := os.MkdirAll(filepath.Dir(config.PromptPreludePath), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// A directory where the prelude file should be cannot be read
+	if err := os.Mkdir(config.PromptPreludePath, 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := New(context.Background(), Options{}); err == nil || !strings.Contains(err.Error(), config.PromptPreludePath) {
+		t.Errorf("New() error = %v, want a %s error", err, config.PromptPreludePath)
+	}
+	newClient(t, Options{NoPrelude: true})
+}
+
+func TestCommitInputDefaults(t *testing.T) {
+	useRepo(t, "commit:\n  language: japanese\n  gitmoji: true\n  max_subject_length: 60\n  scopes: [api, ui]\n")
+	c := newClient(t, Options{})
+
+	input := c.commitInput("DIFF", CommitOptions{Branch: "main"})
+	if input.Diff != "DIFF" || input.Branch != "main" {
+		t.Errorf("diff and branch = %q, %q", input.Diff, input.Branch)
+	}
+	if input.Language != "japanese" || !input.Gitmoji || input.MaxSubjectLength != 60 {
+		t.Errorf("defaults = %q, gitmoji %v, max %d; want the configured values", input.Language, input.Gitmoji, input.MaxSubjectLength)
+	}
+	if strings.Join(input.Scopes, ",") != "api,ui" {
+		t.Errorf("scopes = %q, want the configured scopes", input.Scopes)
+	}
+
+	input = c.commitInput("DIFF", CommitOptions{Language: "english", MaxSubjectLength: 50, Scope: "api", Concise: true})
+	if input.Language != "english" || input.MaxSubjectLength != 50 || input.Scope != "api" || !input.Concise {
+		t.Errorf("overrides = %q, max %d, scope %q, concise %v", input.Language, input.MaxSubjectLength, input.Scope, input.Concise)
+	}
+}
+
+func TestFinalizer(t *testing.T) {
+	const signoff = "Signed-off-by: Jane Doe <jane@example.com>"
+
+	tests := []struct {
+		name    string
+		config  string
+		opts    CommitOptions
+		message string
+		want    string
+	}{
+		{
+			name:    "ticket footer from the branch",
+			config:  "commit:\n  ticket_pattern: \"[A-Z]+-[0-9]+\"\n",
+			opts:    CommitOptions{Branch: "feature/ABC-123-login"},
+			message: "feat: add login",
+			want:    "feat: add login\n\nRefs: ABC-123",
+		},
+		{
+			name:    "ticket scope and configured sign-off",
+			config:  "commit:\n  ticket_pattern: \"[A-Z]+-[0-9]+\"\n  ticket_placement: scope\n  signoff: true\n",
+			opts:    CommitOptions{Branch: "ABC-123"},
+			message: "fix: handle empty diffs",
+			want:    "fix(ABC-123): handle empty diffs\n\n" + signoff,
+		},
+		{
+			name:    "sign-off option",
+			opts:    CommitOptions{Signoff: true},
+			message: "fix: typo",
+			want:    "fix: typo\n\n" + signoff,
+		},
+		{
+			name:    "nothing configured",
+			opts:    CommitOptions{Branch: "feature/ABC-123-login"},
+			message: "fix: typo",
+			want:    "fix: typo",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			useRepo(t, tt.config)
+			c := newClient(t, Options{})
+
+			finalizer, err := c.finalizer(tt.opts)
+			if err != nil {
+				t.Fatalf("finalizer() error = %v", err)
+			}
+			if got := finalizer.Finalize(tt.message); got != tt.want {
+				t.Errorf("Finalize()\n got %q\nwant %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateCommitMessageEmptyDiff(t *testing.T) {
+	useRepo(t, "")
+	c := newClient(t, Options{})
+	if _, err := c.GenerateCommitMessage(context.Background(), "", CommitOptions{}); err == nil {
+		t.Error("GenerateCommitMessage() with an empty diff succeeded")
+	}
+}